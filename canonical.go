@@ -0,0 +1,254 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Canonical returns a brand new Filter equivalent to f in a canonical form, so equivalent filters are equal:
+// ignored filters are removed, nested groups having the same logic are flattened, groups having a single filter
+// are unwrapped, filters of "and" and "or" are sorted and deduplicated, numbers having no fraction become int64,
+// RFC3339 date strings become time.Time in UTC and unary operators have the same value.
+// Fields are kept as is since mongo's fields are case-sensitive, call it after HandleField.
+func (f *Filter) Canonical() Filter {
+	filter, ok := f.canonical()
+	if !ok {
+		return Filter{}
+	}
+	return filter
+}
+
+func (f *Filter) canonical() (Filter, bool) {
+	if len(f.Filters) == 0 {
+		if !filterOperators[f.Operator] {
+			return Filter{}, false
+		}
+		filter := Filter{Field: f.Field, Operator: f.Operator, Value: canonicalValue(f.Value)}
+		_, isString := filter.Value.(string)
+		switch f.Operator {
+		case "startswith", "doesnotstartwith", "contains", "doesnotcontain", "isempty", "isnotempty":
+			// ToAggregateFilter ignores them unless the value is a string.
+			if !isString {
+				return Filter{}, false
+			}
+		}
+		switch f.Operator {
+		case "isnull", "isnotnull":
+			filter.Value = nil
+		case "isempty", "isnotempty":
+			filter.Value = ""
+		}
+		return filter, true
+	}
+	if f.Logic != "and" && f.Logic != "or" && f.Logic != "not" {
+		return Filter{}, false
+	}
+
+	// "not" negates all of its filters joined with "and".
+	logic := f.Logic
+	if logic == "not" {
+		logic = "and"
+	}
+	filters := []Filter{}
+	for i := range f.Filters {
+		filter, ok := f.Filters[i].canonical()
+		if !ok {
+			continue
+		}
+		if len(filter.Filters) != 0 && filter.Logic == logic {
+			filters = append(filters, filter.Filters...)
+			continue
+		}
+		filters = append(filters, filter)
+	}
+	if len(filters) == 0 {
+		return Filter{}, false
+	}
+
+	keys := make([]string, len(filters))
+	for i := range filters {
+		keys[i] = filters[i].canonicalKey()
+	}
+	sort.Sort(filtersByKey{filters, keys})
+	unique := filters[:1]
+	for i := 1; i < len(filters); i++ {
+		if keys[i] != keys[i-1] {
+			unique = append(unique, filters[i])
+		}
+	}
+
+	var filter Filter
+	if len(unique) == 1 {
+		filter = unique[0]
+	} else {
+		filter = Filter{Filters: unique, Logic: logic}
+	}
+	if f.Logic == "not" {
+		return Not(filter), true
+	}
+	return filter, true
+}
+
+// Fingerprint returns SHA-256 (hex) of the canonical form of f, equivalent filters have the same fingerprint.
+func (f *Filter) Fingerprint() string {
+	filter := f.Canonical()
+	return fingerprint(filter.canonicalKey())
+}
+
+// Canonical returns a brand new Sort in a canonical form: elements having no valid dir are removed
+// and only the first element of a field is kept since the next ones don't change the order.
+func (s *Sort) Canonical() Sort {
+	sort := Sort{}
+	for _, v := range *s {
+		if (v.Dir == "asc" || v.Dir == "desc") && !sort.HasField(v.Field) {
+			sort = append(sort, v)
+		}
+	}
+	return sort
+}
+
+// Fingerprint returns SHA-256 (hex) of the canonical form of s.
+func (s *Sort) Fingerprint() string {
+	sort := s.Canonical()
+	return fingerprint(sort.canonicalKey())
+}
+
+// Fingerprint returns SHA-256 (hex) of the canonical form of the whole request: filter, sort (group fields first), paging,
+// group and aggregate. Use it as the cache key of the page of data.
+func (r *Request) Fingerprint() string {
+	sort := r.sort()
+	filter := r.Filter.Canonical()
+	canonicalSort := sort.Canonical()
+	text := filter.canonicalKey() + "|" + canonicalSort.canonicalKey() +
+		"|" + strconv.Itoa(r.Offset()) + "|" + strconv.Itoa(r.Limit())
+	for _, v := range r.Group {
+		text += "|g:" + strconv.Quote(v.Field) + canonicalAggregates(v.Aggregates)
+	}
+	return fingerprint(text + "|a:" + canonicalAggregates(r.Aggregate))
+}
+
+// CountFingerprint returns SHA-256 (hex) of the canonical form of the request's filter,
+// the total count doesn't depend on sort and paging. Use it as the cache key of the total count.
+func (r *Request) CountFingerprint() string {
+	return r.Filter.Fingerprint()
+}
+
+type filtersByKey struct {
+	filters []Filter
+	keys    []string
+}
+
+func (s filtersByKey) Len() int           { return len(s.filters) }
+func (s filtersByKey) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s filtersByKey) Swap(i, j int) {
+	s.filters[i], s.filters[j] = s.filters[j], s.filters[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// canonicalKey encodes filter having typed values, so 1 and "1" are different.
+func (f *Filter) canonicalKey() string {
+	if len(f.Filters) == 0 {
+		if f.Operator == "" {
+			return ""
+		}
+		return "(" + strconv.Quote(f.Field) + " " + f.Operator + " " + canonicalValueKey(f.Value) + ")"
+	}
+	keys := make([]string, len(f.Filters))
+	for i := range f.Filters {
+		keys[i] = f.Filters[i].canonicalKey()
+	}
+	return f.Logic + "(" + strings.Join(keys, ",") + ")"
+}
+
+func (s *Sort) canonicalKey() string {
+	keys := make([]string, len(*s))
+	for i, v := range *s {
+		keys[i] = strconv.Quote(v.Field) + " " + v.Dir
+	}
+	return strings.Join(keys, ",")
+}
+
+func canonicalAggregates(aggregates []AggregateElem) string {
+	keys := []string{}
+	for _, v := range aggregates {
+		keys = append(keys, strconv.Quote(v.Field)+" "+v.Aggregate)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func canonicalValue(value interface{}) interface{} {
+	value = normalizeValue(value)
+	switch v := value.(type) {
+	case string:
+		if t, ok := parseDefaultDate(v); ok {
+			return t.UTC()
+		}
+	case time.Time:
+		return v.UTC()
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64, bson.Decimal128:
+		return v
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = canonicalValue(v[i])
+		}
+		return values
+	}
+	if number, ok := toFloat64(value); ok {
+		if number == math.Trunc(number) && math.Abs(number) < 1<<53 {
+			return int64(number)
+		}
+		return number
+	}
+	return value
+}
+
+func canonicalValueKey(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "s" + strconv.Quote(v)
+	case int64:
+		return "i" + strconv.FormatInt(v, 10)
+	case float64:
+		return "f" + strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return "b" + strconv.FormatBool(v)
+	case time.Time:
+		return "t" + v.Format(time.RFC3339Nano)
+	case bson.ObjectId:
+		return "o" + v.Hex()
+	case bson.Decimal128:
+		return "d" + v.String()
+	case []interface{}:
+		keys := make([]string, len(v))
+		for i := range v {
+			keys[i] = canonicalValueKey(v[i])
+		}
+		return "[" + strings.Join(keys, ",") + "]"
+	}
+	return fmt.Sprintf("%T%#v", value, value)
+}
+
+func fingerprint(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
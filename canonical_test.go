@@ -0,0 +1,139 @@
+package kendohelper_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muktihari/kendohelper"
+)
+
+func TestFilterCanonical(t *testing.T) {
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected kendohelper.Filter
+	}{
+		{
+			name: "sorted, flattened and deduplicated",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Age", "gte", json.Number("25.0"), nil, ""},
+					kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+				}, "and"},
+				kendohelper.Filter{"Name", "like", "x", nil, ""},
+				kendohelper.Filter{"deleted_at", "isnull", "whatever", nil, ""},
+			}, "and"},
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Age", "gte", int64(25), nil, ""},
+				kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+				kendohelper.Filter{"deleted_at", "isnull", nil, nil, ""},
+			}, "and"},
+		},
+		{
+			name: "single filter is unwrapped, time is UTC",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"created_at", "lt", "2019-01-02T07:00:00+07:00", nil, ""},
+				}, "or"},
+			}, "and"},
+			expected: kendohelper.Filter{"created_at", "lt", time.Date(2019, 01, 02, 00, 00, 00, 00, time.UTC), nil, ""},
+		},
+		{
+			name: "not",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"b", "eq", 2.0, nil, ""},
+				kendohelper.Filter{"a", "eq", 1, nil, ""},
+			}, "not"},
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"a", "eq", int64(1), nil, ""},
+					kendohelper.Filter{"b", "eq", int64(2), nil, ""},
+				}, "and"},
+			}, "not"},
+		},
+		{
+			name:     "ignored",
+			filter:   kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"Name", "contains", 1, nil, ""}}, "and"},
+			expected: kendohelper.Filter{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			canonical := tc.filter.Canonical()
+			if !reflect.DeepEqual(canonical, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, canonical)
+			}
+		})
+	}
+}
+
+func TestFilterFingerprint(t *testing.T) {
+	a := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+		kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"Age", "eq", json.Number("1"), nil, ""},
+			kendohelper.Filter{"Age", "eq", 2, nil, ""},
+		}, "or"},
+	}, "and"}
+	b := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"Age", "eq", 2.0, nil, ""},
+			kendohelper.Filter{"Age", "eq", 1.0, nil, ""},
+		}, "or"},
+		kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+	}, "and"}
+	c := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"Age", "eq", "2", nil, ""},
+			kendohelper.Filter{"Age", "eq", "1", nil, ""},
+		}, "or"},
+		kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+	}, "and"}
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("equivalent filters should have the same fingerprint, got %v and %v", a.Fingerprint(), b.Fingerprint())
+	}
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Errorf("numbers and strings should have different fingerprints, got %v", a.Fingerprint())
+	}
+	if len(a.Fingerprint()) != 64 {
+		t.Errorf("fingerprint should be SHA-256 hex, got %v", a.Fingerprint())
+	}
+}
+
+func TestSortCanonical(t *testing.T) {
+	sort := kendohelper.Sort{
+		kendohelper.SortElem{"Name", "asc"},
+		kendohelper.SortElem{"Age", ""},
+		kendohelper.SortElem{"Name", "desc"},
+		kendohelper.SortElem{"Age", "desc"},
+	}
+	expected := kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}, kendohelper.SortElem{"Age", "desc"}}
+	if canonical := sort.Canonical(); !reflect.DeepEqual(canonical, expected) {
+		t.Errorf("canonical should be %v, got %v", expected, canonical)
+	}
+	if sort.Fingerprint() != expected.Fingerprint() {
+		t.Errorf("equivalent sorts should have the same fingerprint")
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	filter := kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"Age", "gt", 20, nil, ""}}, "and"}
+	a := kendohelper.Request{Take: 10, Skip: 10, Filter: filter, Sort: kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}}}
+	b := kendohelper.Request{Page: 2, PageSize: 10, Filter: filter.Canonical(), Sort: kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}}}
+	c := kendohelper.Request{Page: 3, PageSize: 10, Filter: filter, Sort: kendohelper.Sort{kendohelper.SortElem{"Name", "desc"}}}
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("equivalent requests should have the same fingerprint")
+	}
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Errorf("different pages should have different fingerprints")
+	}
+	if a.CountFingerprint() != c.CountFingerprint() {
+		t.Errorf("count fingerprint should only depend on the filter")
+	}
+}
@@ -0,0 +1,324 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// ColumnAction is the action of ColumnPolicy recorded in ColumnAudit.
+type ColumnAction string
+
+const (
+	ColumnRewritten ColumnAction = "rewritten"
+	ColumnDropped   ColumnAction = "dropped"
+	ColumnRejected  ColumnAction = "rejected"
+)
+
+type rolesContextKey struct{}
+
+// WithRoles returns a copy of ctx carrying the caller's roles, used by ColumnPolicy.
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesContextKey{}, roles)
+}
+
+// RolesFromContext returns the roles carried by ctx.
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesContextKey{}).([]string)
+	return roles
+}
+
+// ColumnRule restricts how the callers having Roles filter, sort, group and aggregate by Field.
+type ColumnRule struct {
+	Field string
+	// Roles the rule applies to, empty applies to any caller.
+	Roles []string
+	// Operators allowed on Field, nil allows any operator. Filters having another operator are rewritten by Rewrite, or else denied.
+	Operators []string
+	// Rewrite rewrites a filter whose operator isn't allowed (e.g. "eq" into a range bucket of "gte" and "lt"), false denies it.
+	Rewrite func(filter Filter) (Filter, bool)
+	// Unsortable denies sorting and grouping by Field, unless RewriteSort rewrites it. Grouping is also denied
+	// when Operators doesn't allow "eq", since group values expose the values of Field.
+	Unsortable bool
+	// RewriteSort rewrites a sort element of an unsortable Field (e.g. sort by salary_grade instead), false denies it.
+	// Group elements are rewritten into the field of the rewritten sort element.
+	RewriteSort func(elem SortElem) (SortElem, bool)
+	// Aggregates allowed on Field, nil allows any aggregate, or only "count" when Operators doesn't allow "eq".
+	Aggregates []string
+	// Drop removes the denied filters, sort, group and aggregate elements instead of rejecting the request.
+	Drop bool
+}
+
+// ColumnAudit records a filter, sort, group or aggregate element changed or rejected by ColumnPolicy.
+type ColumnAudit struct {
+	// Path is e.g. filter.filters[0], sort[1], group[0], group[0].aggregates[1] or aggregate[2].
+	Path   string       `json:"path"`
+	Field  string       `json:"field"`
+	Action ColumnAction `json:"action"`
+	// Before and After are the descriptions (see Describe) of the filter or sort element, "group by <field>" of a group element
+	// and "<aggregate> of <field>" of an aggregate element. After is empty unless it's rewritten.
+	Before string `json:"before"`
+	After  string `json:"after,omitempty"`
+}
+
+func (a ColumnAudit) String() string {
+	text := a.Path + ": " + string(a.Action) + " " + a.Before
+	if a.After != "" {
+		text += " into " + a.After
+	}
+	return text
+}
+
+// ColumnPolicyError is returned by ColumnPolicy's Apply when a denied filter, sort, group or aggregate element is rejected.
+type ColumnPolicyError struct {
+	Audit []ColumnAudit
+}
+
+func (e *ColumnPolicyError) Error() string {
+	texts := []string{}
+	for _, v := range e.Audit {
+		if v.Action == ColumnRejected {
+			texts = append(texts, v.Path+": "+v.Before+" is not allowed")
+		}
+	}
+	return "kendohelper: " + strings.Join(texts, "; ")
+}
+
+// ColumnPolicy rewrites or rejects filters, sort, group and aggregate elements according to the caller's roles (see WithRoles).
+// The first rule matching the field and the caller's roles applies, fields having no rule aren't restricted.
+// Rules are on database fields (after aliasing).
+type ColumnPolicy struct {
+	Rules []ColumnRule
+}
+
+// NewColumnPolicy creates ColumnPolicy of rules.
+func NewColumnPolicy(rules ...ColumnRule) *ColumnPolicy {
+	return &ColumnPolicy{Rules: rules}
+}
+
+// Apply applies the rules to the filter, sort, group and aggregate of request and returns the audit of what's changed.
+// When something is rejected, request is kept intact and *ColumnPolicyError is returned along with the audit.
+func (p *ColumnPolicy) Apply(ctx context.Context, request *Request) ([]ColumnAudit, error) {
+	roles := RolesFromContext(ctx)
+	filter, audit := p.applyFilter(roles, request.Filter)
+	sort, sortAudit := p.applySort(roles, request.Sort)
+	audit = append(audit, sortAudit...)
+	group, groupAudit := p.applyGroup(roles, request.Group)
+	audit = append(audit, groupAudit...)
+	aggregate, aggregateAudit := p.applyAggregate(roles, "aggregate", request.Aggregate)
+	audit = append(audit, aggregateAudit...)
+	for _, v := range audit {
+		if v.Action == ColumnRejected {
+			return audit, &ColumnPolicyError{Audit: audit}
+		}
+	}
+	request.Filter, request.Sort, request.Group, request.Aggregate = filter, sort, group, aggregate
+	return audit, nil
+}
+
+// rule returns the first rule of field applying to roles.
+func (p *ColumnPolicy) rule(roles []string, field string) (*ColumnRule, bool) {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Field != field {
+			continue
+		}
+		if len(rule.Roles) == 0 {
+			return rule, true
+		}
+		for _, role := range roles {
+			if containsString(rule.Roles, role) {
+				return rule, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (p *ColumnPolicy) applyFilter(roles []string, filter Filter) (Filter, []ColumnAudit) {
+	audit := []ColumnAudit{}
+	filter = filter.DeepClone()
+	filter.Walk(func(filter Filter, info WalkInfo) (Filter, WalkAction) {
+		if !info.Leaf || !filterOperators[filter.Operator] {
+			return filter, WalkContinue
+		}
+		rule, ok := p.rule(roles, filter.Field)
+		if !ok || rule.Operators == nil || containsString(rule.Operators, filter.Operator) {
+			return filter, WalkContinue
+		}
+		record := ColumnAudit{Path: walkPath(info.Path), Field: filter.Field, Before: filter.Describe(nil)}
+		if rule.Rewrite != nil {
+			if rewritten, ok := rule.Rewrite(filter.DeepClone()); ok && p.allowsFilter(roles, rewritten) {
+				record.Action, record.After = ColumnRewritten, rewritten.Describe(nil)
+				audit = append(audit, record)
+				return rewritten, WalkSkip
+			}
+		}
+		if rule.Drop {
+			record.Action = ColumnDropped
+			audit = append(audit, record)
+			return filter, WalkRemove
+		}
+		record.Action = ColumnRejected
+		audit = append(audit, record)
+		return filter, WalkContinue
+	}, func(filter Filter, info WalkInfo) (Filter, WalkAction) {
+		// groups left empty by dropped filters
+		if info.Leaf && filter.Operator == "" && filter.Logic != "" {
+			return filter, WalkRemove
+		}
+		return filter, WalkContinue
+	})
+	return filter, audit
+}
+
+func (p *ColumnPolicy) applySort(roles []string, sort Sort) (Sort, []ColumnAudit) {
+	audit := []ColumnAudit{}
+	result := Sort{}
+	for i, elem := range sort {
+		rule, ok := p.rule(roles, elem.Field)
+		if !ok || !rule.Unsortable || (elem.Dir != "asc" && elem.Dir != "desc") {
+			result = append(result, elem)
+			continue
+		}
+		record := ColumnAudit{Path: "sort[" + strconv.Itoa(i) + "]", Field: elem.Field, Before: (&Sort{elem}).Describe(nil)}
+		if rule.RewriteSort != nil {
+			if rewritten, ok := rule.RewriteSort(elem); ok && p.allowsSort(roles, rewritten.Field) {
+				record.Action, record.After = ColumnRewritten, (&Sort{rewritten}).Describe(nil)
+				audit = append(audit, record)
+				result = append(result, rewritten)
+				continue
+			}
+		}
+		if rule.Drop {
+			record.Action = ColumnDropped
+		} else {
+			record.Action = ColumnRejected
+		}
+		audit = append(audit, record)
+	}
+	return result, audit
+}
+
+func (p *ColumnPolicy) applyGroup(roles []string, group []GroupElem) ([]GroupElem, []ColumnAudit) {
+	audit := []ColumnAudit{}
+	if group == nil {
+		return nil, audit
+	}
+	result := []GroupElem{}
+	for i, elem := range group {
+		path := "group[" + strconv.Itoa(i) + "]"
+		if !p.allowsGroup(roles, elem.Field) {
+			rule, _ := p.rule(roles, elem.Field)
+			record := ColumnAudit{Path: path, Field: elem.Field, Before: "group by " + elem.Field}
+			if rewritten, ok := p.rewriteGroup(roles, rule, elem); ok {
+				record.Action, record.After = ColumnRewritten, "group by "+rewritten.Field
+				audit = append(audit, record)
+				elem = rewritten
+			} else {
+				record.Action = ColumnDropped
+				if !rule.Drop {
+					record.Action = ColumnRejected
+				}
+				audit = append(audit, record)
+				continue
+			}
+		}
+		aggregates, aggregatesAudit := p.applyAggregate(roles, path+".aggregates", elem.Aggregates)
+		audit = append(audit, aggregatesAudit...)
+		elem.Aggregates = aggregates
+		result = append(result, elem)
+	}
+	return result, audit
+}
+
+// rewriteGroup rewrites elem into the field of the sort element rewritten by RewriteSort.
+func (p *ColumnPolicy) rewriteGroup(roles []string, rule *ColumnRule, elem GroupElem) (GroupElem, bool) {
+	if rule.RewriteSort == nil {
+		return elem, false
+	}
+	dir := elem.Dir
+	if dir != "desc" {
+		dir = "asc"
+	}
+	rewritten, ok := rule.RewriteSort(SortElem{Field: elem.Field, Dir: dir})
+	if !ok || !p.allowsGroup(roles, rewritten.Field) {
+		return elem, false
+	}
+	elem.Field = rewritten.Field
+	return elem, true
+}
+
+func (p *ColumnPolicy) applyAggregate(roles []string, path string, aggregate []AggregateElem) ([]AggregateElem, []ColumnAudit) {
+	audit := []ColumnAudit{}
+	if aggregate == nil {
+		return nil, audit
+	}
+	result := []AggregateElem{}
+	for i, elem := range aggregate {
+		rule, ok := p.rule(roles, elem.Field)
+		if !ok || rule.allowsAggregate(elem.Aggregate) {
+			result = append(result, elem)
+			continue
+		}
+		record := ColumnAudit{Path: path + "[" + strconv.Itoa(i) + "]", Field: elem.Field, Before: elem.Aggregate + " of " + elem.Field}
+		if rule.Drop {
+			record.Action = ColumnDropped
+		} else {
+			record.Action = ColumnRejected
+		}
+		audit = append(audit, record)
+	}
+	return result, audit
+}
+
+// allowsFilter checks whether the rules allow every filter of filter, e.g. the result of Rewrite.
+func (p *ColumnPolicy) allowsFilter(roles []string, filter Filter) bool {
+	if len(filter.Filters) == 0 {
+		if !filterOperators[filter.Operator] {
+			return true
+		}
+		rule, ok := p.rule(roles, filter.Field)
+		return !ok || rule.Operators == nil || containsString(rule.Operators, filter.Operator)
+	}
+	for _, v := range filter.Filters {
+		if !p.allowsFilter(roles, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *ColumnPolicy) allowsSort(roles []string, field string) bool {
+	rule, ok := p.rule(roles, field)
+	return !ok || !rule.Unsortable
+}
+
+func (p *ColumnPolicy) allowsGroup(roles []string, field string) bool {
+	rule, ok := p.rule(roles, field)
+	return !ok || !rule.Unsortable && rule.allowsEq()
+}
+
+// allowsEq checks whether the exact values of Field may be exposed.
+func (r *ColumnRule) allowsEq() bool {
+	return r.Operators == nil || containsString(r.Operators, "eq")
+}
+
+func (r *ColumnRule) allowsAggregate(aggregate string) bool {
+	if r.Aggregates != nil {
+		return containsString(r.Aggregates, aggregate)
+	}
+	return r.allowsEq() || aggregate == "count"
+}
+
+func walkPath(path []int) string {
+	text := "filter"
+	for _, i := range path {
+		text += ".filters[" + strconv.Itoa(i) + "]"
+	}
+	return text
+}
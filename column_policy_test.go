@@ -0,0 +1,232 @@
+package kendohelper_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+func newSalaryPolicy() *kendohelper.ColumnPolicy {
+	return kendohelper.NewColumnPolicy(
+		kendohelper.ColumnRule{Field: "salary", Roles: []string{"admin"}},
+		kendohelper.ColumnRule{
+			Field:     "salary",
+			Operators: []string{"gte", "lt"},
+			Rewrite: func(filter kendohelper.Filter) (kendohelper.Filter, bool) {
+				value, ok := filter.Value.(int)
+				if !ok || filter.Operator != "eq" {
+					return filter, false
+				}
+				bucket := value / 1000 * 1000
+				return kendohelper.And(
+					kendohelper.Filter{Field: "salary", Operator: "gte", Value: bucket},
+					kendohelper.Filter{Field: "salary", Operator: "lt", Value: bucket + 1000},
+				), true
+			},
+			Unsortable: true,
+			RewriteSort: func(elem kendohelper.SortElem) (kendohelper.SortElem, bool) {
+				return kendohelper.SortElem{"salary_grade", elem.Dir}, true
+			},
+		},
+		kendohelper.ColumnRule{Field: "commission_fee", Operators: []string{}, Unsortable: true, Drop: true},
+		kendohelper.ColumnRule{
+			Field:     "bonus",
+			Operators: []string{"gte"},
+			Rewrite: func(filter kendohelper.Filter) (kendohelper.Filter, bool) {
+				filter.Field = "salary" // salary doesn't allow the operator either
+				return filter, true
+			},
+		},
+	)
+}
+
+func TestColumnPolicyApply(t *testing.T) {
+	name := kendohelper.Filter{"name", "eq", "Hari", nil, ""}
+	tt := []struct {
+		name           string
+		roles          []string
+		request        kendohelper.Request
+		expected       kendohelper.Request
+		expectedAudit  []kendohelper.ColumnAudit
+		expectedErrMsg string
+	}{
+		{
+			name:  "admin isn't restricted",
+			roles: []string{"staff", "admin"},
+			request: kendohelper.Request{
+				Filter: kendohelper.Filter{"salary", "eq", 5100, nil, ""},
+				Sort:   kendohelper.Sort{kendohelper.SortElem{"salary", "desc"}},
+			},
+			expected: kendohelper.Request{
+				Filter: kendohelper.Filter{"salary", "eq", 5100, nil, ""},
+				Sort:   kendohelper.Sort{kendohelper.SortElem{"salary", "desc"}},
+			},
+			expectedAudit: []kendohelper.ColumnAudit{},
+		},
+		{
+			name: "rewritten",
+			request: kendohelper.Request{
+				Filter: kendohelper.Filter{"", "", nil, []kendohelper.Filter{name, kendohelper.Filter{"salary", "eq", 5100, nil, ""}}, "and"},
+				Sort:   kendohelper.Sort{kendohelper.SortElem{"name", "asc"}, kendohelper.SortElem{"salary", "desc"}},
+			},
+			expected: kendohelper.Request{
+				Filter: kendohelper.Filter{"", "", nil, []kendohelper.Filter{name, kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{Field: "salary", Operator: "gte", Value: 5000},
+					kendohelper.Filter{Field: "salary", Operator: "lt", Value: 6000},
+				}, "and"}}, "and"},
+				Sort: kendohelper.Sort{kendohelper.SortElem{"name", "asc"}, kendohelper.SortElem{"salary_grade", "desc"}},
+			},
+			expectedAudit: []kendohelper.ColumnAudit{
+				{"filter.filters[1]", "salary", kendohelper.ColumnRewritten, "salary = 5100", "(salary >= 5000 AND salary < 6000)"},
+				{"sort[1]", "salary", kendohelper.ColumnRewritten, "salary descending", "salary_grade descending"},
+			},
+		},
+		{
+			name: "dropped",
+			request: kendohelper.Request{
+				Filter: kendohelper.Filter{"", "", nil, []kendohelper.Filter{name, kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"commission_fee", "gt", 10, nil, ""},
+				}, "or"}}, "and"},
+				Sort: kendohelper.Sort{kendohelper.SortElem{"commission_fee", "asc"}},
+			},
+			expected: kendohelper.Request{
+				Filter: kendohelper.Filter{"", "", nil, []kendohelper.Filter{name}, "and"},
+				Sort:   kendohelper.Sort{},
+			},
+			expectedAudit: []kendohelper.ColumnAudit{
+				{"filter.filters[1].filters[0]", "commission_fee", kendohelper.ColumnDropped, "commission_fee > 10", ""},
+				{"sort[0]", "commission_fee", kendohelper.ColumnDropped, "commission_fee ascending", ""},
+			},
+		},
+		{
+			name: "rejected",
+			request: kendohelper.Request{
+				Filter: kendohelper.Filter{"salary", "neq", 5100, nil, ""},
+			},
+			expected: kendohelper.Request{
+				Filter: kendohelper.Filter{"salary", "neq", 5100, nil, ""},
+			},
+			expectedAudit: []kendohelper.ColumnAudit{
+				{"filter", "salary", kendohelper.ColumnRejected, "salary != 5100", ""},
+			},
+			expectedErrMsg: "kendohelper: filter: salary != 5100 is not allowed",
+		},
+		{
+			name: "rewritten filter is checked",
+			request: kendohelper.Request{
+				Filter: kendohelper.Filter{"bonus", "eq", 100, nil, ""},
+			},
+			expected: kendohelper.Request{
+				Filter: kendohelper.Filter{"bonus", "eq", 100, nil, ""},
+			},
+			expectedAudit: []kendohelper.ColumnAudit{
+				{"filter", "bonus", kendohelper.ColumnRejected, "bonus = 100", ""},
+			},
+			expectedErrMsg: "kendohelper: filter: bonus = 100 is not allowed",
+		},
+		{
+			name: "group and aggregates",
+			request: kendohelper.Request{
+				Group: []kendohelper.GroupElem{
+					kendohelper.GroupElem{"salary", "desc", []kendohelper.AggregateElem{
+						kendohelper.AggregateElem{"commission_fee", "sum"},
+						kendohelper.AggregateElem{"name", "max"},
+					}},
+					kendohelper.GroupElem{"commission_fee", "asc", nil},
+				},
+				Aggregate: []kendohelper.AggregateElem{
+					kendohelper.AggregateElem{"commission_fee", "count"},
+					kendohelper.AggregateElem{"commission_fee", "max"},
+				},
+			},
+			expected: kendohelper.Request{
+				Sort: kendohelper.Sort{},
+				Group: []kendohelper.GroupElem{
+					kendohelper.GroupElem{"salary_grade", "desc", []kendohelper.AggregateElem{kendohelper.AggregateElem{"name", "max"}}},
+				},
+				Aggregate: []kendohelper.AggregateElem{kendohelper.AggregateElem{"commission_fee", "count"}},
+			},
+			expectedAudit: []kendohelper.ColumnAudit{
+				{"group[0]", "salary", kendohelper.ColumnRewritten, "group by salary", "group by salary_grade"},
+				{"group[0].aggregates[0]", "commission_fee", kendohelper.ColumnDropped, "sum of commission_fee", ""},
+				{"group[1]", "commission_fee", kendohelper.ColumnDropped, "group by commission_fee", ""},
+				{"aggregate[1]", "commission_fee", kendohelper.ColumnDropped, "max of commission_fee", ""},
+			},
+		},
+		{
+			name: "aggregate rejected",
+			request: kendohelper.Request{
+				Group:     []kendohelper.GroupElem{kendohelper.GroupElem{"bonus", "asc", nil}},
+				Aggregate: []kendohelper.AggregateElem{kendohelper.AggregateElem{"salary", "count"}, kendohelper.AggregateElem{"salary", "max"}},
+			},
+			expected: kendohelper.Request{
+				Group:     []kendohelper.GroupElem{kendohelper.GroupElem{"bonus", "asc", nil}},
+				Aggregate: []kendohelper.AggregateElem{kendohelper.AggregateElem{"salary", "count"}, kendohelper.AggregateElem{"salary", "max"}},
+			},
+			expectedAudit: []kendohelper.ColumnAudit{
+				{"group[0]", "bonus", kendohelper.ColumnRejected, "group by bonus", ""},
+				{"aggregate[1]", "salary", kendohelper.ColumnRejected, "max of salary", ""},
+			},
+			expectedErrMsg: "kendohelper: group[0]: group by bonus is not allowed; aggregate[1]: max of salary is not allowed",
+		},
+	}
+
+	policy := newSalaryPolicy()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request := tc.request
+			audit, err := policy.Apply(kendohelper.WithRoles(context.Background(), tc.roles...), &request)
+			if tc.expectedErrMsg != "" {
+				if _, ok := err.(*kendohelper.ColumnPolicyError); !ok || err.Error() != tc.expectedErrMsg {
+					t.Errorf("%v error should be %v, got %v", tc.name, tc.expectedErrMsg, err)
+				}
+			} else if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(request, tc.expected) {
+				t.Errorf("%v should be %#v, got %#v", tc.name, tc.expected, request)
+			}
+			if !reflect.DeepEqual(audit, tc.expectedAudit) {
+				t.Errorf("%v audit should be %v, got %v", tc.name, tc.expectedAudit, audit)
+			}
+		})
+	}
+}
+
+func TestHandlerColumnPolicy(t *testing.T) {
+	var audited []kendohelper.ColumnAudit
+	handler := &kendohelper.Handler{
+		ColumnPolicy: newSalaryPolicy(),
+		Audit:        func(r *http.Request, audit []kendohelper.ColumnAudit) { audited = audit },
+		Data: func(ctx context.Context, request kendohelper.Request) (kendohelper.Response, error) {
+			return kendohelper.Response{Data: []toolkit.M{toolkit.M{"sort": request.Sort.Describe(nil)}}, Total: 1}, nil
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/grid?sort[0][field]=salary&sort[0][dir]=asc", nil))
+	if body := strings.TrimSpace(recorder.Body.String()); body != `{"data":[{"sort":"salary_grade ascending"}],"total":1}` {
+		t.Errorf("sort should be rewritten, got %v", body)
+	}
+	if len(audited) != 1 || audited[0].String() != "sort[0]: rewritten salary ascending into salary_grade ascending" {
+		t.Errorf("rewrite should be audited, got %v", audited)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/grid?group[0][field]=salary&group[0][dir]=asc", nil))
+	if len(audited) != 1 || audited[0].String() != "group[0]: rewritten group by salary into group by salary_grade" {
+		t.Errorf("group should be rewritten, got %v", audited)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/grid?filter[logic]=and&filter[filters][0][field]=salary&filter[filters][0][operator]=neq&filter[filters][0][value]=1", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("rejected request should get %v, got %v", http.StatusBadRequest, recorder.Code)
+	}
+}
@@ -0,0 +1,188 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// CountStore stores total counts per collection, implement it to use an external store (e.g. redis).
+type CountStore interface {
+	// Get returns the count of key, ok is false when it's missing or expired.
+	Get(collection, key string) (count int, ok bool)
+	// Set stores the count of key for ttl, zero ttl means no expiration.
+	Set(collection, key string, count int, ttl time.Duration)
+	// Invalidate removes all counts of collection.
+	Invalidate(collection string)
+}
+
+// CountCache memoizes total counts per canonical filter (see Filter's Fingerprint), so equivalent filters share the count.
+type CountCache struct {
+	Store CountStore
+	TTL   time.Duration
+
+	mu sync.Mutex
+	// generations are incremented by Invalidate, so counts started before are dropped.
+	generations map[string]uint64
+}
+
+// NewCountCache creates CountCache of store, a nil store uses an LRUCountStore of 1024 counts.
+func NewCountCache(store CountStore, ttl time.Duration) *CountCache {
+	if store == nil {
+		store = NewLRUCountStore(1024)
+	}
+	return &CountCache{Store: store, TTL: ttl}
+}
+
+// Count returns the cached count of filter on collection, or else calls count (any backend, e.g. dbox, mongo or SQL) and caches its result.
+// Errors aren't cached, neither are counts which were in flight while the collection was invalidated.
+func (c *CountCache) Count(ctx context.Context, collection string, filter Filter, count func(ctx context.Context) (int, error)) (int, error) {
+	key := filter.Fingerprint()
+	if n, ok := c.Store.Get(collection, key); ok {
+		return n, nil
+	}
+	c.mu.Lock()
+	generation := c.generations[collection]
+	c.mu.Unlock()
+
+	n, err := count(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[collection] == generation {
+		c.Store.Set(collection, key, n, c.TTL)
+	}
+	return n, nil
+}
+
+// Invalidate removes all counts of collection, call it after the collection is written.
+func (c *CountCache) Invalidate(collection string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations == nil {
+		c.generations = map[string]uint64{}
+	}
+	c.generations[collection]++
+	c.Store.Invalidate(collection)
+}
+
+// NewCachedSource wraps source so its Count is cached by cache as collection.
+func NewCachedSource[T any](source Source[T], cache *CountCache, collection string) Source[T] {
+	return &cachedSource[T]{source, cache, collection}
+}
+
+type cachedSource[T any] struct {
+	source     Source[T]
+	cache      *CountCache
+	collection string
+}
+
+func (s *cachedSource[T]) Query(ctx context.Context, filter Filter, sort Sort, skip, take int) ([]T, error) {
+	return s.source.Query(ctx, filter, sort, skip, take)
+}
+
+func (s *cachedSource[T]) Count(ctx context.Context, filter Filter) (int, error) {
+	return s.cache.Count(ctx, s.collection, filter, func(ctx context.Context) (int, error) {
+		return s.source.Count(ctx, filter)
+	})
+}
+
+// LRUCountStore is CountStore in memory keeping at most Capacity counts, the least recently used are evicted.
+// It's safe for concurrent use.
+type LRUCountStore struct {
+	mu          sync.Mutex
+	capacity    int
+	order       *list.List
+	collections map[string]map[string]*list.Element
+}
+
+type countEntry struct {
+	collection string
+	key        string
+	count      int
+	expires    time.Time
+}
+
+// NewLRUCountStore creates LRUCountStore keeping at most capacity counts, a capacity below 1 keeps nothing.
+func NewLRUCountStore(capacity int) *LRUCountStore {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &LRUCountStore{
+		capacity:    capacity,
+		order:       list.New(),
+		collections: map[string]map[string]*list.Element{},
+	}
+}
+
+// Get implements CountStore.
+func (s *LRUCountStore) Get(collection, key string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.collections[collection][key]
+	if !ok {
+		return 0, false
+	}
+	entry := elem.Value.(*countEntry)
+	if !entry.expires.IsZero() && !time.Now().Before(entry.expires) {
+		s.remove(elem)
+		return 0, false
+	}
+	s.order.MoveToFront(elem)
+	return entry.count, true
+}
+
+// Set implements CountStore.
+func (s *LRUCountStore) Set(collection, key string, count int, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := &countEntry{collection: collection, key: key, count: count}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	if elem, ok := s.collections[collection][key]; ok {
+		elem.Value = entry
+		s.order.MoveToFront(elem)
+		return
+	}
+	if s.collections[collection] == nil {
+		s.collections[collection] = map[string]*list.Element{}
+	}
+	s.collections[collection][key] = s.order.PushFront(entry)
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+}
+
+// Invalidate implements CountStore.
+func (s *LRUCountStore) Invalidate(collection string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, elem := range s.collections[collection] {
+		s.order.Remove(elem)
+	}
+	delete(s.collections, collection)
+}
+
+// Len returns the number of counts stored, expired ones included until they're removed.
+func (s *LRUCountStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *LRUCountStore) remove(elem *list.Element) {
+	entry := s.order.Remove(elem).(*countEntry)
+	delete(s.collections[entry.collection], entry.key)
+	if len(s.collections[entry.collection]) == 0 {
+		delete(s.collections, entry.collection)
+	}
+}
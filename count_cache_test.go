@@ -0,0 +1,157 @@
+package kendohelper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/muktihari/kendohelper"
+)
+
+func TestCountCache(t *testing.T) {
+	cache := kendohelper.NewCountCache(nil, 0)
+	calls := 0
+	count := func(ctx context.Context) (int, error) {
+		calls++
+		return 10, nil
+	}
+	nameAndAge := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+		kendohelper.Filter{"Age", "gte", 21, nil, ""},
+	}, "and"}
+	ageAndName := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"Age", "gte", 21.0, nil, ""},
+		kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+	}, "and"}
+
+	tt := []struct {
+		name       string
+		collection string
+		filter     kendohelper.Filter
+		invalidate string
+		calls      int
+	}{
+		{"first count", "users", nameAndAge, "", 1},
+		{"equivalent filter is cached", "users", ageAndName, "", 1},
+		{"other collection", "orders", nameAndAge, "", 2},
+		{"other collection invalidated", "users", nameAndAge, "orders", 2},
+		{"collection invalidated", "users", nameAndAge, "users", 3},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.invalidate != "" {
+				cache.Invalidate(tc.invalidate)
+			}
+			n, err := cache.Count(context.Background(), tc.collection, tc.filter, count)
+			if err != nil || n != 10 {
+				t.Fatalf("%v should be 10, got %v (%v)", tc.name, n, err)
+			}
+			if calls != tc.calls {
+				t.Errorf("%v calls should be %v, got %v", tc.name, tc.calls, calls)
+			}
+		})
+	}
+
+	errCount := errors.New("count failed")
+	for i := 0; i < 2; i++ {
+		_, err := cache.Count(context.Background(), "failing", nameAndAge, func(ctx context.Context) (int, error) {
+			calls++
+			return 0, errCount
+		})
+		if err != errCount {
+			t.Errorf("error should be %v, got %v", errCount, err)
+		}
+	}
+	if calls != 5 {
+		t.Errorf("errors shouldn't be cached, calls should be 5, got %v", calls)
+	}
+}
+
+func TestCountCacheInvalidateInFlight(t *testing.T) {
+	cache := kendohelper.NewCountCache(nil, 0)
+	filter := kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"Name", "eq", "Hari", nil, ""}}, "and"}
+	n, err := cache.Count(context.Background(), "users", filter, func(ctx context.Context) (int, error) {
+		cache.Invalidate("users") // the collection is written while counting
+		return 10, nil
+	})
+	if err != nil || n != 10 {
+		t.Fatalf("count should be 10, got %v (%v)", n, err)
+	}
+	n, err = cache.Count(context.Background(), "users", filter, func(ctx context.Context) (int, error) {
+		return 11, nil
+	})
+	if err != nil || n != 11 {
+		t.Errorf("count in flight while invalidated shouldn't be cached, should be 11, got %v (%v)", n, err)
+	}
+}
+
+func TestLRUCountStore(t *testing.T) {
+	store := kendohelper.NewLRUCountStore(2)
+	store.Set("users", "a", 1, 0)
+	store.Set("users", "b", 2, 0)
+	store.Get("users", "a")
+	store.Set("users", "c", 3, 0)
+
+	tt := []struct {
+		name     string
+		key      string
+		expected int
+		ok       bool
+	}{
+		{"recently used is kept", "a", 1, true},
+		{"least recently used is evicted", "b", 0, false},
+		{"latest is kept", "c", 3, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			n, ok := store.Get("users", tc.key)
+			if n != tc.expected || ok != tc.ok {
+				t.Errorf("%v should be %v %v, got %v %v", tc.name, tc.expected, tc.ok, n, ok)
+			}
+		})
+	}
+
+	store.Set("orders", "d", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if n, ok := store.Get("orders", "d"); ok {
+		t.Errorf("expired count should be missing, got %v", n)
+	}
+	if store.Len() != 1 {
+		t.Errorf("len should be 1, got %v", store.Len())
+	}
+}
+
+func TestLRUCountStoreNegativeCapacity(t *testing.T) {
+	store := kendohelper.NewLRUCountStore(-1)
+	store.Set("users", "a", 1, 0)
+	if n, ok := store.Get("users", "a"); ok || store.Len() != 0 {
+		t.Errorf("nothing should be kept, got %v %v", n, store.Len())
+	}
+}
+
+func TestCachedSource(t *testing.T) {
+	source := kendohelper.NewMemorySource(sourceUser{"Hari", 25}, sourceUser{"Surya", 30})
+	cache := kendohelper.NewCountCache(nil, time.Minute)
+	cached := kendohelper.NewCachedSource[sourceUser](source, cache, "users")
+	filter := kendohelper.Filter{"Age", "gte", 21, nil, ""}
+
+	count, err := cached.Count(context.Background(), filter)
+	if err != nil || count != 2 {
+		t.Fatalf("count should be 2, got %v (%v)", count, err)
+	}
+	source.Rows = append(source.Rows, sourceUser{"Radit", 27})
+	if count, _ = cached.Count(context.Background(), filter); count != 2 {
+		t.Errorf("cached count should be 2, got %v", count)
+	}
+	cache.Invalidate("users")
+	if count, _ = cached.Count(context.Background(), filter); count != 3 {
+		t.Errorf("invalidated count should be 3, got %v", count)
+	}
+	rows, _ := cached.Query(context.Background(), filter, nil, 0, 0)
+	if len(rows) != 3 {
+		t.Errorf("rows should be 3, got %v", len(rows))
+	}
+}
@@ -0,0 +1,204 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://developer.mozilla.org/en-US/docs/Web/JavaScript/Reference/Global_Objects/Date/toString
+ * https://docs.microsoft.com/en-us/dotnet/standard/datetime/system-text-json-support
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eaciit/dbox"
+	"github.com/eaciit/toolkit"
+)
+
+// KendoDateLayout is the layout of javascript's Date.toString(), kendo's default when a date is sent as is,
+// e.g. "Tue Jan 01 2019 00:00:00 GMT+0700 (Western Indonesia Time)". The zone name in parentheses is ignored.
+const KendoDateLayout = "Mon Jan 02 2006 15:04:05 GMT-0700"
+
+// DefaultDateLayouts are the layouts of NewDateParser: RFC3339, Date.toString() and ISO 8601 without zone.
+var DefaultDateLayouts = []string{
+	time.RFC3339Nano,
+	KendoDateLayout,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseDefaultDate parses the string values left unconverted by the converters (ToAggregateFilter, ToDBOXFilter, Match, etc.).
+// It only recognizes RFC3339, whose zone makes it independent of the user's location, other strings are compared as is.
+// Use DateParser for more layouts, "/Date()/" and zone names.
+func parseDefaultDate(s string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, s)
+	return t, err == nil
+}
+
+// DateParser parses date values: strings in one of its Layouts (tried in order), "/Date(1546300800000)/" and,
+// for EpochFields only, numbers of unix epoch milliseconds.
+type DateParser struct {
+	Layouts []string
+	// Location of layouts having no zone, nil means UTC.
+	Location *time.Location
+	// EpochFields are the fields whose numbers are unix epoch milliseconds.
+	EpochFields []string
+}
+
+// NewDateParser creates DateParser of DefaultDateLayouts in location.
+func NewDateParser(location *time.Location, epochFields ...string) *DateParser {
+	return &DateParser{Layouts: DefaultDateLayouts, Location: location, EpochFields: epochFields}
+}
+
+// In returns a copy of p parsing layouts having no zone in location, e.g. the location of the user.
+func (p *DateParser) In(location *time.Location) *DateParser {
+	parser := *p
+	parser.Location = location
+	return &parser
+}
+
+// Parse parses value: a string, or a number (int, int64, float64 or json.Number) of unix epoch milliseconds.
+func (p *DateParser) Parse(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		return p.parseString(v)
+	case json.Number:
+		if ms, err := v.Int64(); err == nil {
+			return epochMillis(ms), true
+		}
+	case int:
+		return epochMillis(int64(v)), true
+	case int64:
+		return epochMillis(v), true
+	case float64:
+		if v == float64(int64(v)) {
+			return epochMillis(int64(v)), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Convert returns a brand new Filter whose date values of "eq", "neq", "lt", "lte", "gt" and "gte" are parsed into time.Time,
+// so every converter (VirtualFields' included) gets the same dates. Values of the other operators are kept as is, they compare
+// text (e.g. "contains") or have no value.
+func (p *DateParser) Convert(filter Filter) Filter {
+	filter = filter.DeepClone()
+	filter.Handle(func(filter Filter) Filter {
+		switch filter.Operator {
+		case "eq", "neq", "lt", "lte", "gt", "gte":
+		default:
+			return filter
+		}
+		epoch := containsString(p.EpochFields, filter.Field)
+		filter.Value = p.convertValue(normalizeValue(filter.Value), epoch)
+		return filter
+	})
+	return filter
+}
+
+// ToAggregateFilter converts filter to $match like Filter's ToAggregateFilter, with the date values parsed by p (see Convert).
+func (p *DateParser) ToAggregateFilter(filter Filter) toolkit.M {
+	filter = p.Convert(filter)
+	return filter.ToAggregateFilter()
+}
+
+// ToDBOXFilter converts filter to dbox.Filter like Filter's ToDBOXFilter, with the date values parsed by p (see Convert).
+func (p *DateParser) ToDBOXFilter(filter Filter) *dbox.Filter {
+	filter = p.Convert(filter)
+	return filter.ToDBOXFilter()
+}
+
+// Match checks whether row matches filter like Filter's Match, with the date values parsed by p (see Convert).
+func (p *DateParser) Match(filter Filter, row interface{}) bool {
+	filter = p.Convert(filter)
+	return filter.Match(row)
+}
+
+func (p *DateParser) convertValue(value interface{}, epoch bool) interface{} {
+	if values, ok := value.([]interface{}); ok {
+		converted := make([]interface{}, len(values))
+		for i := range values {
+			converted[i] = p.convertValue(values[i], epoch)
+		}
+		return converted
+	}
+	if _, ok := value.(string); !ok && !epoch {
+		return value
+	}
+	if t, ok := p.Parse(value); ok {
+		return t
+	}
+	return value
+}
+
+func (p *DateParser) parseString(s string) (time.Time, bool) {
+	if strings.HasPrefix(s, "/Date(") && strings.HasSuffix(s, ")/") {
+		return parseMSDate(s[len("/Date(") : len(s)-len(")/")])
+	}
+	location := p.Location
+	if location == nil {
+		location = time.UTC
+	}
+	// Date.toString() ends with the zone name, e.g. " (Western Indonesia Time)"
+	if i := strings.Index(s, " ("); i > 0 && strings.HasSuffix(s, ")") {
+		s = s[:i]
+	}
+	for _, layout := range p.Layouts {
+		if t, err := time.ParseInLocation(layout, s, location); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseMSDate parses the milliseconds of "/Date(1546300800000)/", optionally followed by the offset (e.g. +0700) which doesn't change the instant.
+func parseMSDate(s string) (time.Time, bool) {
+	if i := strings.LastIndexAny(s, "+-"); i > 0 {
+		s = s[:i]
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return epochMillis(ms), true
+}
+
+func epochMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
+
+type locationContextKey struct{}
+
+// WithLocation returns a copy of ctx carrying the location of the user, used by Handler to parse dates.
+func WithLocation(ctx context.Context, location *time.Location) context.Context {
+	return context.WithValue(ctx, locationContextKey{}, location)
+}
+
+// LocationFromHeader returns a func getting the location of the request from the header name having IANA time zone, e.g. "X-Time-Zone: Asia/Jakarta".
+// Missing or unknown time zones give nil.
+func LocationFromHeader(name string) func(r *http.Request) *time.Location {
+	return func(r *http.Request) *time.Location {
+		zone := r.Header.Get(name)
+		if zone == "" {
+			return nil
+		}
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil
+		}
+		return location
+	}
+}
+
+// LocationFromContext returns the location carried by ctx.
+func LocationFromContext(ctx context.Context) (*time.Location, bool) {
+	location, ok := ctx.Value(locationContextKey{}).(*time.Location)
+	return location, ok && location != nil
+}
@@ -0,0 +1,145 @@
+package kendohelper_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eaciit/dbox"
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+func TestDateParserParse(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	parser := kendohelper.NewDateParser(jakarta)
+	newYear := time.Date(2019, 1, 1, 0, 0, 0, 0, jakarta)
+
+	tt := []struct {
+		name     string
+		value    interface{}
+		expected time.Time
+		ok       bool
+	}{
+		{"RFC3339", "2018-12-31T17:00:00Z", newYear, true},
+		{"Date.toString()", "Tue Jan 01 2019 00:00:00 GMT+0700 (Western Indonesia Time)", newYear, true},
+		{"ISO without zone is in location", "2019-01-01T00:00:00", newYear, true},
+		{"date only", "2019-01-01", newYear, true},
+		{"/Date()/", "/Date(1546275600000)/", newYear, true},
+		{"/Date()/ having offset", "/Date(1546275600000+0700)/", newYear, true},
+		{"epoch millis", json.Number("1546275600000"), newYear, true},
+		{"not a date", "Hari", time.Time{}, false},
+		{"bool", true, time.Time{}, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parser.Parse(tc.value)
+			if ok != tc.ok || !got.Equal(tc.expected) {
+				t.Errorf("%v should be %v %v, got %v %v", tc.name, tc.expected, tc.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestDateParserConvert(t *testing.T) {
+	parser := kendohelper.NewDateParser(time.UTC, "created_ms")
+	newYear := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected interface{}
+	}{
+		{"string", kendohelper.Filter{"created_at", "gte", "2019-01-01", nil, ""}, newYear},
+		{"epoch field", kendohelper.Filter{"created_ms", "lt", json.Number("1546300800000"), nil, ""}, newYear},
+		{"number of other field", kendohelper.Filter{"age", "eq", json.Number("20"), nil, ""}, int64(20)},
+		{"contains is kept", kendohelper.Filter{"name", "contains", "2019-01-01", nil, ""}, "2019-01-01"},
+		{"values", kendohelper.Filter{"created_at", "eq", []interface{}{"2019-01-01"}, nil, ""}, []interface{}{newYear}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := parser.Convert(tc.filter)
+			if !reflect.DeepEqual(filter.Value, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, filter.Value)
+			}
+		})
+	}
+}
+
+func TestDateParserConverters(t *testing.T) {
+	filter := kendohelper.Filter{"created_at", "gte", "2019-01-01", nil, ""}
+	if match := filter.ToAggregateFilter(); !reflect.DeepEqual(match, toolkit.M{"created_at": toolkit.M{"$gte": "2019-01-01"}}) {
+		t.Errorf("date only shouldn't be parsed by default, got %v", match)
+	}
+	for _, value := range []string{"/Date(0)/", "2019-01-01T00:00:00Z (hello)"} {
+		text := kendohelper.Filter{"name", "eq", value, nil, ""}
+		if match := text.ToAggregateFilter(); !reflect.DeepEqual(match, toolkit.M{"name": value}) {
+			t.Errorf("%v shouldn't be parsed by default, got %v", value, match)
+		}
+	}
+
+	wib := time.FixedZone("WIB", 7*60*60)
+	parser := kendohelper.NewDateParser(time.UTC).In(wib)
+	newYear := time.Date(2019, 1, 1, 0, 0, 0, 0, wib)
+	if match := parser.ToAggregateFilter(filter); !reflect.DeepEqual(match, toolkit.M{"created_at": toolkit.M{"$gte": newYear}}) {
+		t.Errorf("ToAggregateFilter should parse in %v, got %v", wib, match)
+	}
+	if dboxFilter := parser.ToDBOXFilter(filter); !reflect.DeepEqual(dboxFilter, dbox.Gte("created_at", newYear)) {
+		t.Errorf("ToDBOXFilter should parse in %v, got %v", wib, dboxFilter)
+	}
+	if !parser.Match(filter, toolkit.M{"created_at": newYear}) || parser.Match(filter, toolkit.M{"created_at": newYear.Add(-time.Second)}) {
+		t.Errorf("Match should parse in %v", wib)
+	}
+	if filter.Value != "2019-01-01" {
+		t.Errorf("filter should not be modified, got %v", filter.Value)
+	}
+}
+
+func TestHandlerDates(t *testing.T) {
+	handler := &kendohelper.Handler{
+		Dates: kendohelper.NewDateParser(time.UTC),
+		Location: func(r *http.Request) *time.Location {
+			if r.Header.Get("X-Offset") == "7" {
+				return time.FixedZone("WIB", 7*60*60)
+			}
+			return nil
+		},
+		Data: func(ctx context.Context, request kendohelper.Request) (kendohelper.Response, error) {
+			return kendohelper.Response{Data: []toolkit.M{toolkit.M{"filter": request.Filter.Expression()}}, Total: 1}, nil
+		},
+	}
+	query := "/grid?filter[logic]=and&filter[filters][0][field]=created_at&filter[filters][0][operator]=gte&filter[filters][0][value]=2019-01-01"
+
+	tt := []struct {
+		name     string
+		header   string
+		location *time.Location
+		body     string
+	}{
+		{"default location", "", nil, `{"data":[{"filter":"created_at \u003e= \"2019-01-01T00:00:00Z\""}],"total":1}`},
+		{"location of the header", "7", nil, `{"data":[{"filter":"created_at \u003e= \"2019-01-01T00:00:00+07:00\""}],"total":1}`},
+		{"location of the context", "", time.FixedZone("", -5*60*60), `{"data":[{"filter":"created_at \u003e= \"2019-01-01T00:00:00-05:00\""}],"total":1}`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", query, nil)
+			r.Header.Set("X-Offset", tc.header)
+			if tc.location != nil {
+				r = r.WithContext(kendohelper.WithLocation(r.Context(), tc.location))
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, r)
+			if body := strings.TrimSpace(recorder.Body.String()); body != tc.body {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.body, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.telerik.com/kendo-ui/api/javascript/ui/grid/configuration/filterable.operators
+ */
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SymbolOperators describes operators with symbols, suitable for audit logs.
+// It only affects DefaultDescriber, so it can be translated without changing what ParseFilter understands.
+var SymbolOperators = map[string]string{
+	"eq":               "=",
+	"neq":              "!=",
+	"lt":               "<",
+	"lte":              "<=",
+	"gt":               ">",
+	"gte":              ">=",
+	"startswith":       "starts with",
+	"doesnotstartwith": "does not start with",
+	"endswith":         "ends with",
+	"doesnotendwith":   "does not end with",
+	"contains":         "contains",
+	"doesnotcontain":   "does not contain",
+	"isnull":           "is null",
+	"isnotnull":        "is not null",
+	"isempty":          "is empty",
+	"isnotempty":       "is not empty",
+}
+
+// KendoOperators describes operators the way Kendo's filter menu does.
+// Copy and translate it to localize the description.
+var KendoOperators = map[string]string{
+	"eq":               "Is equal to",
+	"neq":              "Is not equal to",
+	"lt":               "Is less than",
+	"lte":              "Is less than or equal to",
+	"gt":               "Is greater than",
+	"gte":              "Is greater than or equal to",
+	"startswith":       "Starts with",
+	"doesnotstartwith": "Does not start with",
+	"endswith":         "Ends with",
+	"doesnotendwith":   "Does not end with",
+	"contains":         "Contains",
+	"doesnotcontain":   "Does not contain",
+	"isnull":           "Is null",
+	"isnotnull":        "Is not null",
+	"isempty":          "Is empty",
+	"isnotempty":       "Is not empty",
+}
+
+// unaryOperators are operators which don't use the filter's value.
+var unaryOperators = map[string]bool{
+	"isnull":     true,
+	"isnotnull":  true,
+	"isempty":    true,
+	"isnotempty": true,
+}
+
+// Describer renders Filter and Sort as human-readable text.
+// FieldLabel may be nil, missing words fall back to the original operator, logic or dir.
+type Describer struct {
+	FieldLabel func(field string) string
+	Operators  map[string]string
+	Logics     map[string]string
+	Dirs       map[string]string
+}
+
+// DefaultDescriber is used when Describe is given a nil Describer.
+var DefaultDescriber = &Describer{
+	Operators: SymbolOperators,
+	Logics:    map[string]string{"and": "AND", "or": "OR", "not": "NOT"},
+	Dirs:      map[string]string{"asc": "ascending", "desc": "descending"},
+}
+
+// Describe renders Filter as human-readable expression, e.g. (Name contains "hari" AND Age >= 25).
+// Filters ignored by the converters (unrecognized operator or logic) are left out.
+func (f *Filter) Describe(d *Describer) string {
+	if d == nil {
+		d = DefaultDescriber
+	}
+	if len(f.Filters) == 0 {
+		if !filterOperators[f.Operator] {
+			return ""
+		}
+		text := d.field(f.Field) + " " + d.word(d.Operators, f.Operator)
+		if !unaryOperators[f.Operator] {
+			text += " " + describeValue(f.Value)
+		}
+		return text
+	}
+
+	if f.Logic != "and" && f.Logic != "or" && f.Logic != "not" {
+		return ""
+	}
+	texts := []string{}
+	for i := range f.Filters {
+		if text := f.Filters[i].Describe(d); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) == 0 {
+		return ""
+	}
+	if f.Logic == "not" {
+		// "not" negates all of its filters, they are joined with "and".
+		text := strings.Join(texts, " "+d.word(d.Logics, "and")+" ")
+		if len(texts) > 1 || !strings.HasPrefix(text, "(") {
+			text = "(" + text + ")"
+		}
+		return d.word(d.Logics, "not") + " " + text
+	}
+	if len(texts) == 1 {
+		return texts[0]
+	}
+	return "(" + strings.Join(texts, " "+d.word(d.Logics, f.Logic)+" ") + ")"
+}
+
+// Describe renders Sort as human-readable text, e.g. Name ascending, Age descending.
+func (s *Sort) Describe(d *Describer) string {
+	if d == nil {
+		d = DefaultDescriber
+	}
+	texts := []string{}
+	for _, v := range *s {
+		if v.Dir != "asc" && v.Dir != "desc" {
+			continue
+		}
+		texts = append(texts, d.field(v.Field)+" "+d.word(d.Dirs, v.Dir))
+	}
+	return strings.Join(texts, ", ")
+}
+
+func (d *Describer) field(field string) string {
+	if d.FieldLabel == nil {
+		return field
+	}
+	return d.FieldLabel(field)
+}
+
+func (d *Describer) word(words map[string]string, key string) string {
+	if word, ok := words[key]; ok {
+		return word
+	}
+	return key
+}
+
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
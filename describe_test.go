@@ -0,0 +1,129 @@
+package kendohelper_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muktihari/kendohelper"
+)
+
+func TestFilterDescribe(t *testing.T) {
+	tt := []struct {
+		name      string
+		filter    kendohelper.Filter
+		describer *kendohelper.Describer
+		expected  string
+	}{
+		{
+			name: "default describer",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "contains", "hari", nil, ""},
+				kendohelper.Filter{"Age", "gte", json.Number("25"), nil, ""},
+			}, "and"},
+			expected: `(Name contains "hari" AND Age >= 25)`,
+		},
+		{
+			name: "nested group, unary operator and date",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "isnotnull", nil, nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"created_at", "lt", time.Date(2019, 01, 02, 00, 00, 00, 00, time.UTC), nil, ""},
+					kendohelper.Filter{"created_at", "isnull", nil, nil, ""},
+				}, "or"},
+			}, "and"},
+			expected: `(Name is not null AND (created_at < 2019-01-02T00:00:00Z OR created_at is null))`,
+		},
+		{
+			name: "not",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+					kendohelper.Filter{"Age", "lt", 25, nil, ""},
+				}, "not"},
+			}, "and"},
+			expected: `NOT (Name = "Hari" AND Age < 25)`,
+		},
+		{
+			name: "unrecognized operator and logic are left out",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "ne", "Hari", nil, ""},
+				kendohelper.Filter{"Age", "eq", 25, nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Age", "eq", 30, nil, ""},
+				}, "xor"},
+			}, "and"},
+			expected: `Age = 25`,
+		},
+		{
+			name: "localized field labels and operators",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"name", "eq", "Hari", nil, ""},
+				kendohelper.Filter{"age", "gt", 25, nil, ""},
+			}, "or"},
+			describer: &kendohelper.Describer{
+				FieldLabel: func(field string) string { return strings.ToUpper(field[:1]) + field[1:] },
+				Operators:  map[string]string{"eq": "Sama dengan"},
+				Logics:     map[string]string{"or": "ATAU"},
+			},
+			expected: `(Name Sama dengan "Hari" ATAU Age gt 25)`,
+		},
+		{
+			name: "kendo operators",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "doesnotcontain", "x", nil, ""},
+			}, "and"},
+			describer: &kendohelper.Describer{Operators: kendohelper.KendoOperators},
+			expected:  `Name Does not contain "x"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			text := tc.filter.Describe(tc.describer)
+			if text != tc.expected {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, text)
+			}
+		})
+	}
+}
+
+func TestSortDescribe(t *testing.T) {
+	tt := []struct {
+		name      string
+		sort      kendohelper.Sort
+		describer *kendohelper.Describer
+		expected  string
+	}{
+		{
+			name: "default describer",
+			sort: kendohelper.Sort{
+				kendohelper.SortElem{"Name", "asc"},
+				kendohelper.SortElem{"Age", ""},
+				kendohelper.SortElem{"CreatedAt", "desc"},
+			},
+			expected: "Name ascending, CreatedAt descending",
+		},
+		{
+			name: "localized",
+			sort: kendohelper.Sort{
+				kendohelper.SortElem{"name", "asc"},
+			},
+			describer: &kendohelper.Describer{
+				FieldLabel: func(field string) string { return "Nama" },
+				Dirs:       map[string]string{"asc": "naik"},
+			},
+			expected: "Nama naik",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			text := tc.sort.Describe(tc.describer)
+			if text != tc.expected {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, text)
+			}
+		})
+	}
+}
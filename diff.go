@@ -0,0 +1,169 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import "strconv"
+
+// ChangeKind is the kind of FilterChange and SortChange.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+	// ChangeMoved is a sort element having the same dir but another position relative to the others.
+	ChangeMoved ChangeKind = "moved"
+)
+
+// FilterChange is a condition (a filter joined with "and" on the root) which is added, removed or modified.
+// Old is nil when it's added, New is nil when it's removed. Modified conditions are on the same field.
+type FilterChange struct {
+	Kind ChangeKind `json:"kind"`
+	Old  *Filter    `json:"old,omitempty"`
+	New  *Filter    `json:"new,omitempty"`
+}
+
+// SortChange is a sort element which is added, removed, has another dir (modified) or another position (moved).
+type SortChange struct {
+	Kind   ChangeKind `json:"kind"`
+	Field  string     `json:"field"`
+	OldDir string     `json:"oldDir,omitempty"`
+	NewDir string     `json:"newDir,omitempty"`
+}
+
+// RequestDiff is the changes from a request to the next one.
+type RequestDiff struct {
+	Filter    []FilterChange `json:"filter,omitempty"`
+	Sort      []SortChange   `json:"sort,omitempty"`
+	Page      bool           `json:"page,omitempty"`
+	Group     bool           `json:"group,omitempty"`
+	Aggregate bool           `json:"aggregate,omitempty"`
+}
+
+// Changed reports whether anything is changed.
+func (d *RequestDiff) Changed() bool {
+	return len(d.Filter) != 0 || len(d.Sort) != 0 || d.Page || d.Group || d.Aggregate
+}
+
+// TotalChanged reports whether the total count may be changed, it's only changed by the filter,
+// so the cached total can be reused when only sort, page, group or aggregate are changed.
+func (d *RequestDiff) TotalChanged() bool {
+	return len(d.Filter) != 0
+}
+
+// Diff returns the changes from f to next. Both are compared in their canonical form (see Canonical),
+// so reordered or equivalent conditions aren't changes.
+func (f *Filter) Diff(next Filter) []FilterChange {
+	prev, curr := f.Canonical(), next.Canonical()
+	oldConds, newConds := prev.conditions(), curr.conditions()
+	oldKeys, newKeys := conditionKeys(oldConds), conditionKeys(newConds)
+
+	added := []int{}
+	for i := range newConds {
+		if !oldKeys[newConds[i].canonicalKey()] {
+			added = append(added, i)
+		}
+	}
+
+	changes := []FilterChange{}
+	for i := range oldConds {
+		if newKeys[oldConds[i].canonicalKey()] {
+			continue
+		}
+		change := FilterChange{Kind: ChangeRemoved, Old: &oldConds[i]}
+		for j, k := range added {
+			if isLeaf(&oldConds[i]) && isLeaf(&newConds[k]) && oldConds[i].Field == newConds[k].Field {
+				change = FilterChange{Kind: ChangeModified, Old: &oldConds[i], New: &newConds[k]}
+				added = append(added[:j], added[j+1:]...)
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+	for _, k := range added {
+		changes = append(changes, FilterChange{Kind: ChangeAdded, New: &newConds[k]})
+	}
+	return changes
+}
+
+// Diff returns the changes from s to next. Both are compared in their canonical form (see Canonical).
+func (s *Sort) Diff(next Sort) []SortChange {
+	prev, curr := s.Canonical(), next.Canonical()
+	oldDirs, newDirs := map[string]string{}, map[string]string{}
+	for _, v := range prev {
+		oldDirs[v.Field] = v.Dir
+	}
+	for _, v := range curr {
+		newDirs[v.Field] = v.Dir
+	}
+
+	changes := []SortChange{}
+	// Positions are compared among the fields on both, so adding or removing a field doesn't move the others.
+	oldCommon := []string{}
+	for _, v := range prev {
+		if _, ok := newDirs[v.Field]; !ok {
+			changes = append(changes, SortChange{Kind: ChangeRemoved, Field: v.Field, OldDir: v.Dir})
+			continue
+		}
+		oldCommon = append(oldCommon, v.Field)
+	}
+	common := 0
+	for _, v := range curr {
+		oldDir, ok := oldDirs[v.Field]
+		switch {
+		case !ok:
+			changes = append(changes, SortChange{Kind: ChangeAdded, Field: v.Field, NewDir: v.Dir})
+			continue
+		case oldDir != v.Dir:
+			changes = append(changes, SortChange{Kind: ChangeModified, Field: v.Field, OldDir: oldDir, NewDir: v.Dir})
+		case oldCommon[common] != v.Field:
+			changes = append(changes, SortChange{Kind: ChangeMoved, Field: v.Field, OldDir: oldDir, NewDir: v.Dir})
+		}
+		common++
+	}
+	return changes
+}
+
+// Diff returns the changes from r to next: filter, sort, page (offset and limit), group and aggregate.
+func (r *Request) Diff(next Request) RequestDiff {
+	return RequestDiff{
+		Filter:    r.Filter.Diff(next.Filter),
+		Sort:      r.Sort.Diff(next.Sort),
+		Page:      r.Offset() != next.Offset() || r.Limit() != next.Limit(),
+		Group:     canonicalGroups(r.Group) != canonicalGroups(next.Group),
+		Aggregate: canonicalAggregates(r.Aggregate) != canonicalAggregates(next.Aggregate),
+	}
+}
+
+// conditions returns the filters joined with "and" of the canonical filter.
+func (f *Filter) conditions() []Filter {
+	switch {
+	case len(f.Filters) != 0 && f.Logic == "and":
+		return f.Filters
+	case len(f.Filters) == 0 && f.Operator == "":
+		return nil
+	}
+	return []Filter{*f}
+}
+
+func conditionKeys(filters []Filter) map[string]bool {
+	keys := map[string]bool{}
+	for i := range filters {
+		keys[filters[i].canonicalKey()] = true
+	}
+	return keys
+}
+
+func isLeaf(f *Filter) bool {
+	return len(f.Filters) == 0
+}
+
+func canonicalGroups(groups []GroupElem) string {
+	text := ""
+	for _, v := range groups {
+		text += "|" + strconv.Quote(v.Field) + " " + v.Dir + " " + canonicalAggregates(v.Aggregates)
+	}
+	return text
+}
@@ -0,0 +1,123 @@
+package kendohelper_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/muktihari/kendohelper"
+)
+
+func TestFilterDiff(t *testing.T) {
+	name := kendohelper.Filter{"Name", "eq", "Hari", nil, ""}
+	age := kendohelper.Filter{"Age", "gte", int64(21), nil, ""}
+	age30 := kendohelper.Filter{"Age", "gte", int64(30), nil, ""}
+	city := kendohelper.Filter{"City", "eq", "Jakarta", nil, ""}
+	and := func(filters ...kendohelper.Filter) kendohelper.Filter {
+		return kendohelper.Filter{"", "", "", filters, "and"}
+	}
+
+	tt := []struct {
+		name     string
+		old      kendohelper.Filter
+		new      kendohelper.Filter
+		expected []kendohelper.FilterChange
+	}{
+		{"reordered", and(name, age), and(age, name), []kendohelper.FilterChange{}},
+		{"empty to empty", kendohelper.Filter{}, kendohelper.Filter{}, []kendohelper.FilterChange{}},
+		{"added", name, and(name, age), []kendohelper.FilterChange{
+			{Kind: kendohelper.ChangeAdded, New: &age},
+		}},
+		{"removed", and(name, age), age, []kendohelper.FilterChange{
+			{Kind: kendohelper.ChangeRemoved, Old: &name},
+		}},
+		{"modified", and(name, age), and(name, age30), []kendohelper.FilterChange{
+			{Kind: kendohelper.ChangeModified, Old: &age, New: &age30},
+		}},
+		{"removed and added", and(name, age), and(age, city), []kendohelper.FilterChange{
+			{Kind: kendohelper.ChangeRemoved, Old: &name},
+			{Kind: kendohelper.ChangeAdded, New: &city},
+		}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			changes := tc.old.Diff(tc.new)
+			if !reflect.DeepEqual(changes, tc.expected) {
+				t.Errorf("%v should be %+v, got %+v", tc.name, tc.expected, changes)
+			}
+		})
+	}
+}
+
+func TestSortDiff(t *testing.T) {
+	tt := []struct {
+		name     string
+		old      kendohelper.Sort
+		new      kendohelper.Sort
+		expected []kendohelper.SortChange
+	}{
+		{"same", kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}}, kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}}, []kendohelper.SortChange{}},
+		{"added", nil, kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}}, []kendohelper.SortChange{
+			{kendohelper.ChangeAdded, "Name", "", "asc"},
+		}},
+		{"removed", kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}, kendohelper.SortElem{"Age", "desc"}}, kendohelper.Sort{kendohelper.SortElem{"Age", "desc"}}, []kendohelper.SortChange{
+			{kendohelper.ChangeRemoved, "Name", "asc", ""},
+		}},
+		{"modified", kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}}, kendohelper.Sort{kendohelper.SortElem{"Name", "desc"}}, []kendohelper.SortChange{
+			{kendohelper.ChangeModified, "Name", "asc", "desc"},
+		}},
+		{"moved", kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}, kendohelper.SortElem{"Age", "desc"}}, kendohelper.Sort{kendohelper.SortElem{"Age", "desc"}, kendohelper.SortElem{"Name", "asc"}}, []kendohelper.SortChange{
+			{kendohelper.ChangeMoved, "Age", "desc", "desc"},
+			{kendohelper.ChangeMoved, "Name", "asc", "asc"},
+		}},
+		{"invalid dir is ignored", kendohelper.Sort{kendohelper.SortElem{"Name", ""}}, nil, []kendohelper.SortChange{}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			changes := tc.old.Diff(tc.new)
+			if !reflect.DeepEqual(changes, tc.expected) {
+				t.Errorf("%v should be %+v, got %+v", tc.name, tc.expected, changes)
+			}
+		})
+	}
+}
+
+func TestRequestDiff(t *testing.T) {
+	request := kendohelper.Request{
+		Take:   10,
+		Skip:   0,
+		Filter: kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+		Sort:   kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}},
+	}
+	nextPage := request
+	nextPage.Skip = 10
+	resorted := request
+	resorted.Sort = kendohelper.Sort{kendohelper.SortElem{"Name", "desc"}}
+	refiltered := request
+	refiltered.Filter = kendohelper.Filter{"Name", "eq", "Surya", nil, ""}
+	grouped := request
+	grouped.Group = []kendohelper.GroupElem{{Field: "City", Dir: "asc"}}
+
+	tt := []struct {
+		name         string
+		next         kendohelper.Request
+		changed      bool
+		totalChanged bool
+	}{
+		{"same", request, false, false},
+		{"page", nextPage, true, false},
+		{"sort", resorted, true, false},
+		{"group", grouped, true, false},
+		{"filter", refiltered, true, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := request.Diff(tc.next)
+			if diff.Changed() != tc.changed || diff.TotalChanged() != tc.totalChanged {
+				t.Errorf("%v should be %v %v, got %v %v (%+v)", tc.name, tc.changed, tc.totalChanged, diff.Changed(), diff.TotalChanged(), diff)
+			}
+		})
+	}
+}
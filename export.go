@@ -0,0 +1,249 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.telerik.com/kendo-ui/api/javascript/ui/grid/configuration/columns
+ * https://docs.microsoft.com/en-us/office/open-xml/structure-of-a-spreadsheetml-document
+ */
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// MaxXLSXRows is the maximum number of rows of a worksheet, header included.
+const MaxXLSXRows = 1048576
+
+// Column is a column of the exported file.
+type Column struct {
+	Field string
+	// Title is the header of the column, Field is used when it's empty.
+	Title string
+	// Format formats the value as text: a time layout (e.g. "2006-01-02") for time.Time, otherwise a fmt verb (e.g. "%.2f").
+	// Values without Format keep their type in XLSX.
+	Format string
+}
+
+// Iterator iterates the rows to be exported, e.g. *mgo.Iter. Next decodes the next row into result (a *toolkit.M).
+type Iterator interface {
+	Next(result interface{}) bool
+	Close() error
+}
+
+// ToExportPipeline builds Mongo Pipeline returning all filtered rows in the grid's order, without paging:
+//
+//	[...stages, $match, $sort]
+//
+// Iterate it (e.g. collection.Pipe(pipe).AllowDiskUse().Iter()) into ExportCSV or ExportXLSX.
+func (r *Request) ToExportPipeline(stages ...toolkit.M) []toolkit.M {
+	pipe := r.matchPipeline(stages)
+	sort := r.sort()
+	if aggregateSort := sort.ToAggregateSort(); len(aggregateSort) != 0 {
+		pipe = append(pipe, toolkit.M{"$sort": aggregateSort})
+	}
+	return pipe
+}
+
+// ExportCSV writes the rows of iter as CSV to w, one row at a time, then closes iter.
+// Texts (not numbers) starting with "=", "+", "-", "@", tab or carriage return are prefixed with "'" so they aren't evaluated
+// as formulas by spreadsheets.
+func ExportCSV(w io.Writer, columns []Column, iter Iterator) error {
+	writer := csv.NewWriter(w)
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = escapeFormula(column.title())
+	}
+	err := writer.Write(record)
+
+	row := toolkit.M{}
+	for err == nil && iter.Next(&row) {
+		for i, column := range columns {
+			value, _ := FieldValue(row, column.Field)
+			record[i] = column.text(value)
+			if _, ok := textValue(value).(string); ok {
+				record[i] = escapeFormula(record[i])
+			}
+		}
+		err = writer.Write(record)
+		row = toolkit.M{}
+	}
+	if err == nil {
+		writer.Flush()
+		err = writer.Error()
+	}
+	return closeIterator(iter, err)
+}
+
+// ExportXLSX writes the rows of iter as XLSX (a single worksheet) to w, one row at a time, then closes iter.
+// Numbers, booleans and time.Time (as date, by its wall clock) keep their type unless their Column has Format.
+func ExportXLSX(w io.Writer, columns []Column, iter Iterator) error {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		if err := writeZipFile(archive, part[0], part[1]); err != nil {
+			return closeIterator(iter, err)
+		}
+	}
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return closeIterator(iter, err)
+	}
+
+	sheet := bufio.NewWriter(file)
+	sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row>`)
+	for _, column := range columns {
+		writeXLSXCell(sheet, column.title())
+	}
+	sheet.WriteString(`</row>`)
+
+	rows := 1
+	row := toolkit.M{}
+	for iter.Next(&row) {
+		if rows++; rows > MaxXLSXRows {
+			return closeIterator(iter, fmt.Errorf("kendohelper: exceeds maximum rows of xlsx of %d", MaxXLSXRows))
+		}
+		sheet.WriteString(`<row>`)
+		for _, column := range columns {
+			value, _ := FieldValue(row, column.Field)
+			if column.Format != "" {
+				value = column.text(value)
+			}
+			writeXLSXCell(sheet, value)
+		}
+		if _, err := sheet.WriteString(`</row>`); err != nil {
+			return closeIterator(iter, err)
+		}
+		row = toolkit.M{}
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	if err := sheet.Flush(); err != nil {
+		return closeIterator(iter, err)
+	}
+	return closeIterator(iter, archive.Close())
+}
+
+func (c *Column) title() string {
+	if c.Title == "" {
+		return c.Field
+	}
+	return c.Title
+}
+
+func (c *Column) text(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if c.Format != "" {
+			return v.Format(c.Format)
+		}
+		return v.Format(time.RFC3339)
+	case bson.ObjectId:
+		value = v.Hex()
+		if c.Format == "" {
+			return v.Hex()
+		}
+	case string:
+		if c.Format == "" {
+			return v
+		}
+	}
+	if c.Format != "" {
+		return fmt.Sprintf(c.Format, value)
+	}
+	return fmt.Sprint(value)
+}
+
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func closeIterator(iter Iterator, err error) error {
+	if closeErr := iter.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func writeZipFile(archive *zip.Writer, name, content string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(file, xml.Header+content)
+	return err
+}
+
+func writeXLSXCell(w *bufio.Writer, value interface{}) {
+	if number, ok := toFloat64(value); ok {
+		w.WriteString(`<c><v>` + strconv.FormatFloat(number, 'f', -1, 64) + `</v></c>`)
+		return
+	}
+	switch v := value.(type) {
+	case nil:
+		w.WriteString(`<c/>`)
+		return
+	case bool:
+		b := "0"
+		if v {
+			b = "1"
+		}
+		w.WriteString(`<c t="b"><v>` + b + `</v></c>`)
+		return
+	case time.Time:
+		// Dates are the number of days since 1899-12-30, styled with the date format (s="1"). XLSX has no zone,
+		// so the wall clock is rebuilt in UTC: subtracting in v's location would include its historical offsets (e.g. LMT).
+		wall := time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), time.UTC)
+		base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+		days := float64(wall.Sub(base)) / float64(24*time.Hour)
+		w.WriteString(`<c s="1"><v>` + strconv.FormatFloat(days, 'f', -1, 64) + `</v></c>`)
+		return
+	case bson.ObjectId:
+		value = v.Hex()
+	}
+	w.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(w, []byte(fmt.Sprint(value)))
+	w.WriteString(`</t></is></c>`)
+}
+
+var xlsxParts = [][2]string{
+	{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="1"><font/></fonts>` +
+		`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+		`<borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf/><xf numFmtId="164" applyNumberFormat="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
@@ -0,0 +1,217 @@
+package kendohelper_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type sliceIterator struct {
+	rows   []toolkit.M
+	closed bool
+	err    error
+}
+
+func (it *sliceIterator) Next(result interface{}) bool {
+	if len(it.rows) == 0 {
+		return false
+	}
+	*result.(*toolkit.M) = it.rows[0]
+	it.rows = it.rows[1:]
+	return true
+}
+
+func (it *sliceIterator) Close() error {
+	it.closed = true
+	return it.err
+}
+
+var exportColumns = []kendohelper.Column{
+	kendohelper.Column{"Name", "Full Name", ""},
+	kendohelper.Column{"Age", "", ""},
+	kendohelper.Column{"Salary", "Salary", "%.2f"},
+	kendohelper.Column{"Joined", "Joined", "2006-01-02"},
+	kendohelper.Column{"Address.City", "City", ""},
+	kendohelper.Column{"Active", "Active", ""},
+}
+
+func newExportRows() []toolkit.M {
+	return []toolkit.M{
+		toolkit.M{"Name": "Hari", "Age": 25, "Salary": 1000.5, "Joined": time.Date(2019, 01, 02, 12, 00, 00, 00, time.UTC),
+			"Address": bson.M{"City": "Jakarta"}, "Active": true},
+		toolkit.M{"Name": "=HYPERLINK(\"x\")", "Age": -1, "Salary": nil, "Active": false},
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	iter := &sliceIterator{rows: newExportRows()}
+	buffer := &bytes.Buffer{}
+	if err := kendohelper.ExportCSV(buffer, exportColumns, iter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Full Name,Age,Salary,Joined,City,Active\n" +
+		"Hari,25,1000.50,2019-01-02,Jakarta,true\n" +
+		"\"'=HYPERLINK(\"\"x\"\")\",-1,,,,false\n"
+	if buffer.String() != expected {
+		t.Errorf("csv should be %q, got %q", expected, buffer.String())
+	}
+	if !iter.closed {
+		t.Errorf("iterator should be closed")
+	}
+
+	errClose := errors.New("cursor not found")
+	err := kendohelper.ExportCSV(&bytes.Buffer{}, exportColumns, &sliceIterator{err: errClose})
+	if err != errClose {
+		t.Errorf("error of closing should be %v, got %v", errClose, err)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	iter := &sliceIterator{rows: newExportRows()}
+	buffer := &bytes.Buffer{}
+	if err := kendohelper.ExportXLSX(buffer, exportColumns, iter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !iter.closed {
+		t.Errorf("iterator should be closed")
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("xlsx should be a zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content, _ := io.ReadAll(reader)
+		files[file.Name] = string(content)
+	}
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	expectedNames := []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"}
+	for _, name := range expectedNames {
+		if _, ok := files[name]; !ok {
+			t.Errorf("xlsx should have %v, got %v", name, names)
+		}
+	}
+
+	expectedRows := []string{
+		`<row><c t="inlineStr"><is><t xml:space="preserve">Full Name</t></is></c><c t="inlineStr"><is><t xml:space="preserve">Age</t></is></c>`,
+		`<row><c t="inlineStr"><is><t xml:space="preserve">Hari</t></is></c><c><v>25</v></c><c t="inlineStr"><is><t xml:space="preserve">1000.50</t></is></c>` +
+			`<c t="inlineStr"><is><t xml:space="preserve">2019-01-02</t></is></c><c t="inlineStr"><is><t xml:space="preserve">Jakarta</t></is></c><c t="b"><v>1</v></c></row>`,
+		`<row><c t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;x&#34;)</t></is></c><c><v>-1</v></c><c t="inlineStr"><is><t xml:space="preserve"></t></is></c>` +
+			`<c t="inlineStr"><is><t xml:space="preserve"></t></is></c><c/><c t="b"><v>0</v></c></row>`,
+	}
+	for _, row := range expectedRows {
+		if !strings.Contains(files["xl/worksheets/sheet1.xml"], row) {
+			t.Errorf("sheet should contain %v, got %v", row, files["xl/worksheets/sheet1.xml"])
+		}
+	}
+}
+
+func TestExportXLSXDate(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	tt := []struct {
+		name     string
+		joined   time.Time
+		expected string
+	}{
+		{"utc", time.Date(2019, 01, 02, 12, 00, 00, 00, time.UTC), `<c s="1"><v>43467.5</v></c>`},
+		{"wall clock of the location", time.Date(2020, 07, 01, 00, 00, 00, 00, jakarta), `<c s="1"><v>44013</v></c>`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			iter := &sliceIterator{rows: []toolkit.M{toolkit.M{"Joined": tc.joined}}}
+			buffer := &bytes.Buffer{}
+			if err := kendohelper.ExportXLSX(buffer, []kendohelper.Column{kendohelper.Column{Field: "Joined"}}, iter); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			archive, _ := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+			for _, file := range archive.File {
+				if file.Name != "xl/worksheets/sheet1.xml" {
+					continue
+				}
+				reader, _ := file.Open()
+				content, _ := io.ReadAll(reader)
+				if !strings.Contains(string(content), tc.expected) {
+					t.Errorf("%v should be %v, got %v", tc.name, tc.expected, string(content))
+				}
+			}
+		})
+	}
+}
+
+func TestExportObjectIdAndFormulas(t *testing.T) {
+	type status string
+	rows := func() []toolkit.M {
+		return []toolkit.M{
+			toolkit.M{"_id": bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c3"), "Note": "\t=1+1", "Status": status("-x")},
+			toolkit.M{"Note": "\r=cmd", "Status": status("open")},
+		}
+	}
+	columns := []kendohelper.Column{
+		kendohelper.Column{Field: "_id"},
+		kendohelper.Column{Field: "Note"},
+		kendohelper.Column{Field: "Status"},
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := kendohelper.ExportCSV(buffer, columns, &sliceIterator{rows: rows()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "_id,Note,Status\n" +
+		"5c2c1b7e3f1e4a0001a1b2c3,'\t=1+1,'-x\n" +
+		",\"'\r=cmd\",open\n"
+	if buffer.String() != expected {
+		t.Errorf("csv should be %q, got %q", expected, buffer.String())
+	}
+
+	buffer.Reset()
+	if err := kendohelper.ExportXLSX(buffer, columns, &sliceIterator{rows: rows()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	archive, _ := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	for _, file := range archive.File {
+		if file.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		reader, _ := file.Open()
+		content, _ := io.ReadAll(reader)
+		if expected := `<t xml:space="preserve">5c2c1b7e3f1e4a0001a1b2c3</t>`; !strings.Contains(string(content), expected) {
+			t.Errorf("ObjectId should be %v, got %v", expected, string(content))
+		}
+	}
+}
+
+func TestRequestToExportPipeline(t *testing.T) {
+	request := kendohelper.Request{
+		Take:   10,
+		Sort:   kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}},
+		Filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"Age", "gt", 20, nil, ""}}, "and"},
+	}
+	expected := []toolkit.M{
+		toolkit.M{"$match": toolkit.M{"$and": []toolkit.M{toolkit.M{"Age": toolkit.M{"$gt": 20}}}}},
+		toolkit.M{"$sort": bson.D{{Name: "Name", Value: 1}}},
+	}
+	if pipe := request.ToExportPipeline(); !reflect.DeepEqual(pipe, expected) {
+		t.Errorf("pipeline should be %v, got %v", expected, pipe)
+	}
+}
@@ -0,0 +1,490 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ParseError is returned by ParseFilter when the expression is invalid.
+// Offset is the byte offset in the expression where the error occurs.
+type ParseError struct {
+	Offset  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("kendohelper: parse error at offset %d: %s", e.Offset, e.Message)
+}
+
+// symbolOperators are comparison symbols understood by ParseFilter.
+var symbolOperators = map[string]string{
+	"=":  "eq",
+	"==": "eq",
+	"!=": "neq",
+	"<>": "neq",
+	"<":  "lt",
+	"<=": "lte",
+	">":  "gt",
+	">=": "gte",
+}
+
+// expressionOperators are operators written by Expression with symbols or words,
+// the other kendo operators are written by their name.
+var expressionOperators = map[string]string{
+	"eq":         "=",
+	"neq":        "!=",
+	"lt":         "<",
+	"lte":        "<=",
+	"gt":         ">",
+	"gte":        ">=",
+	"isnull":     "is null",
+	"isnotnull":  "is not null",
+	"isempty":    "is empty",
+	"isnotempty": "is not empty",
+}
+
+// ParseFilter parses a textual filter expression into Filter, e.g.
+//
+//	status = "open" and (amount > 100 or priority in ("high", "urgent"))
+//
+// Conditions are written as field, operator and value. The operator is either a symbol
+// (=, !=, <, <=, >, >=) or any kendo operator name (contains, doesnotstartwith, ...).
+// Fields can be quoted with backticks, strings with double quotes, keywords are case-insensitive.
+// "field in (a, b)" is expanded into "or" filters with "eq" operator,
+// "field is [not] null" and "field is [not] empty" are the unary operators, "not" negates a condition or a group.
+// The result is always a group, just like kendo's filter.
+func ParseFilter(expression string) (Filter, error) {
+	p := &parser{lexer: &lexer{input: expression}}
+	if err := p.next(); err != nil {
+		return Filter{}, err
+	}
+	if p.token.kind == tokenEOF {
+		return Filter{}, nil
+	}
+	filter, err := p.parseOr()
+	if err != nil {
+		return Filter{}, err
+	}
+	if p.token.kind != tokenEOF {
+		return Filter{}, p.errorf("unexpected %s", p.token)
+	}
+	if filter.Logic != "and" && filter.Logic != "or" {
+		filter = Filter{Filters: []Filter{filter}, Logic: "and"}
+	}
+	return filter, nil
+}
+
+// Expression formats Filter as expression understood by ParseFilter.
+// Filters ignored by the converters (unrecognized operator or logic) are left out.
+func (f *Filter) Expression() string {
+	return f.expression(false)
+}
+
+func (f *Filter) expression(nested bool) string {
+	if len(f.Filters) == 0 {
+		if !filterOperators[f.Operator] {
+			return ""
+		}
+		field := formatField(f.Field)
+		operator, ok := expressionOperators[f.Operator]
+		if !ok {
+			operator = f.Operator
+		}
+		if unaryOperators[f.Operator] {
+			return field + " " + operator
+		}
+		return field + " " + operator + " " + formatValue(f.Value)
+	}
+
+	if f.Logic != "and" && f.Logic != "or" && f.Logic != "not" {
+		return ""
+	}
+	if in := f.inExpression(); in != "" {
+		return in
+	}
+	texts := []string{}
+	for i := range f.Filters {
+		if text := f.Filters[i].expression(f.Logic != "not" || len(f.Filters) > 1); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	if f.Logic == "not" {
+		if len(texts) == 0 {
+			return ""
+		}
+		return "not (" + strings.Join(texts, " and ") + ")"
+	}
+	text := strings.Join(texts, " "+f.Logic+" ")
+	if nested && len(texts) > 1 {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// inExpression formats "or" filters having "eq" operator on the same field as "field in (...)".
+func (f *Filter) inExpression() string {
+	if f.Logic != "or" || len(f.Filters) < 2 {
+		return ""
+	}
+	values := make([]string, len(f.Filters))
+	for i, filter := range f.Filters {
+		if len(filter.Filters) != 0 || filter.Operator != "eq" || filter.Field != f.Filters[0].Field {
+			return ""
+		}
+		values[i] = formatValue(filter.Value)
+	}
+	return formatField(f.Filters[0].Field) + " in (" + strings.Join(values, ", ") + ")"
+}
+
+func formatField(field string) string {
+	if isIdentifier(field) && !isKeyword(field) {
+		return field
+	}
+	return "`" + strings.Replace(field, "`", "", -1) + "`"
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case time.Time:
+		return strconv.Quote(v.Format(time.RFC3339Nano))
+	case json.Number:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !isIdentifierRune(r, i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentifierRune(r rune, first bool) bool {
+	if r == '_' || r == '$' || unicode.IsLetter(r) {
+		return true
+	}
+	return !first && (r == '.' || unicode.IsDigit(r))
+}
+
+func isKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case "and", "or", "in", "is", "not", "null", "empty", "true", "false":
+		return true
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenField
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+type lexer struct {
+	input  string
+	offset int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.offset < len(l.input) && unicode.IsSpace(rune(l.input[l.offset])) {
+		l.offset++
+	}
+	start := l.offset
+	if start >= len(l.input) {
+		return token{kind: tokenEOF, offset: start}, nil
+	}
+
+	c := l.input[start]
+	switch {
+	case c == '"':
+		end := start + 1
+		for end < len(l.input) && l.input[end] != '"' {
+			if l.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(l.input) {
+			return token{}, &ParseError{start, "unterminated string"}
+		}
+		text, err := strconv.Unquote(l.input[start : end+1])
+		if err != nil {
+			return token{}, &ParseError{start, "invalid string " + l.input[start:end+1]}
+		}
+		l.offset = end + 1
+		return token{tokenString, text, start}, nil
+	case c == '`':
+		end := strings.IndexByte(l.input[start+1:], '`')
+		if end < 0 {
+			return token{}, &ParseError{start, "unterminated field"}
+		}
+		l.offset = start + end + 2
+		return token{tokenField, l.input[start+1 : start+end+1], start}, nil
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		end := start + 1
+		for end < len(l.input) && strings.IndexByte("0123456789.eE+-", l.input[end]) >= 0 {
+			end++
+		}
+		text := l.input[start:end]
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return token{}, &ParseError{start, "invalid number " + text}
+		}
+		l.offset = end
+		return token{tokenNumber, strings.TrimPrefix(text, "+"), start}, nil
+	case strings.IndexByte("(),", c) >= 0:
+		l.offset++
+		return token{tokenSymbol, string(c), start}, nil
+	case strings.IndexByte("=!<>", c) >= 0:
+		for _, symbol := range []string{"==", "!=", "<>", "<=", ">=", "=", "<", ">"} {
+			if strings.HasPrefix(l.input[start:], symbol) {
+				l.offset += len(symbol)
+				return token{tokenSymbol, symbol, start}, nil
+			}
+		}
+	}
+
+	end := start
+	for _, r := range l.input[start:] {
+		if !isIdentifierRune(r, end == start) {
+			break
+		}
+		end += len(string(r))
+	}
+	if end == start {
+		return token{}, &ParseError{start, "unexpected character " + strconv.Quote(l.input[start:start+1])}
+	}
+	l.offset = end
+	return token{tokenIdent, l.input[start:end], start}, nil
+}
+
+type tokenizer interface {
+	next() (token, error)
+}
+
+type parser struct {
+	lexer tokenizer
+	token token
+}
+
+func (p *parser) next() error {
+	token, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = token
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &ParseError{p.token.offset, fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expect(symbol string) error {
+	if p.token.kind != tokenSymbol || p.token.text != symbol {
+		return p.errorf("expected %q, got %s", symbol, p.token)
+	}
+	return p.next()
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	return p.parseLogic("or", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	return p.parseLogic("and", p.parseUnary)
+}
+
+func (p *parser) parseLogic(logic string, parseOperand func() (Filter, error)) (Filter, error) {
+	filter, err := parseOperand()
+	if err != nil || !p.token.is(logic) {
+		return filter, err
+	}
+	group := Filter{Filters: []Filter{filter}, Logic: logic}
+	for p.token.is(logic) {
+		if err := p.next(); err != nil {
+			return Filter{}, err
+		}
+		filter, err := parseOperand()
+		if err != nil {
+			return Filter{}, err
+		}
+		group.Filters = append(group.Filters, filter)
+	}
+	return group, nil
+}
+
+func (p *parser) parseUnary() (Filter, error) {
+	if p.token.is("not") {
+		if err := p.next(); err != nil {
+			return Filter{}, err
+		}
+		filter, err := p.parseUnary()
+		if err != nil {
+			return Filter{}, err
+		}
+		return Not(filter), nil
+	}
+	if p.token.kind == tokenSymbol && p.token.text == "(" {
+		if err := p.next(); err != nil {
+			return Filter{}, err
+		}
+		filter, err := p.parseOr()
+		if err != nil {
+			return Filter{}, err
+		}
+		return filter, p.expect(")")
+	}
+	return p.parseCondition()
+}
+
+func (p *parser) parseCondition() (Filter, error) {
+	if (p.token.kind != tokenIdent || isKeyword(p.token.text)) && p.token.kind != tokenField {
+		return Filter{}, p.errorf("expected field, got %s", p.token)
+	}
+	filter := Filter{Field: p.token.text}
+	if err := p.next(); err != nil {
+		return Filter{}, err
+	}
+
+	switch {
+	case p.token.kind == tokenSymbol && symbolOperators[p.token.text] != "":
+		filter.Operator = symbolOperators[p.token.text]
+	case p.token.is("in"):
+		return p.parseIn(filter.Field)
+	case p.token.is("is"):
+		return p.parseIs(filter.Field)
+	case p.token.kind == tokenIdent && filterOperators[strings.ToLower(p.token.text)]:
+		filter.Operator = strings.ToLower(p.token.text)
+		if unaryOperators[filter.Operator] {
+			return filter, p.next()
+		}
+	default:
+		return Filter{}, p.errorf("expected operator, got %s", p.token)
+	}
+	if err := p.next(); err != nil {
+		return Filter{}, err
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return Filter{}, err
+	}
+	filter.Value = value
+	return filter, nil
+}
+
+func (p *parser) parseIn(field string) (Filter, error) {
+	if err := p.next(); err != nil {
+		return Filter{}, err
+	}
+	if err := p.expect("("); err != nil {
+		return Filter{}, err
+	}
+	group := Filter{Logic: "or"}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return Filter{}, err
+		}
+		group.Filters = append(group.Filters, Filter{Field: field, Operator: "eq", Value: value})
+		if p.token.kind != tokenSymbol || p.token.text != "," {
+			break
+		}
+		if err := p.next(); err != nil {
+			return Filter{}, err
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return Filter{}, err
+	}
+	if len(group.Filters) == 1 {
+		return group.Filters[0], nil
+	}
+	return group, nil
+}
+
+func (p *parser) parseIs(field string) (Filter, error) {
+	if err := p.next(); err != nil {
+		return Filter{}, err
+	}
+	operator := "is"
+	if p.token.is("not") {
+		operator += "not"
+		if err := p.next(); err != nil {
+			return Filter{}, err
+		}
+	}
+	switch {
+	case p.token.is("null"):
+		operator += "null"
+	case p.token.is("empty"):
+		operator += "empty"
+	default:
+		return Filter{}, p.errorf("expected null or empty, got %s", p.token)
+	}
+	filter := Filter{Field: field, Operator: operator}
+	if operator == "isempty" || operator == "isnotempty" {
+		// the converters only match empty strings
+		filter.Value = ""
+	}
+	return filter, p.next()
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	var value interface{}
+	switch {
+	case p.token.kind == tokenString:
+		value = p.token.text
+	case p.token.kind == tokenNumber:
+		value = json.Number(p.token.text)
+	case p.token.is("true"):
+		value = true
+	case p.token.is("false"):
+		value = false
+	case p.token.is("null"):
+		value = nil
+	default:
+		return nil, p.errorf("expected value, got %s", p.token)
+	}
+	return value, p.next()
+}
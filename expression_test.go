@@ -0,0 +1,258 @@
+package kendohelper_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+func TestParseFilter(t *testing.T) {
+	tt := []struct {
+		name       string
+		expression string
+		expected   kendohelper.Filter
+	}{
+		{
+			name:       "empty",
+			expression: "  ",
+			expected:   kendohelper.Filter{},
+		},
+		{
+			name:       "single condition is wrapped in a group",
+			expression: `Age >= 25`,
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Age", "gte", json.Number("25"), nil, ""},
+			}, "and"},
+		},
+		{
+			name:       "and binds tighter than or, in is expanded",
+			expression: `status = "open" and (amount > 100 or priority in ("high","urgent"))`,
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"status", "eq", "open", nil, ""},
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"amount", "gt", json.Number("100"), nil, ""},
+					kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+						kendohelper.Filter{"priority", "eq", "high", nil, ""},
+						kendohelper.Filter{"priority", "eq", "urgent", nil, ""},
+					}, "or"},
+				}, "or"},
+			}, "and"},
+		},
+		{
+			name:       "operator names, unary operators and keywords are case-insensitive",
+			expression: "Name CONTAINS \"har\\\"i\" OR `order by` isnotnull or deleted_at IS NOT NULL or note is empty and active != false",
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Name", "contains", `har"i`, nil, ""},
+				kendohelper.Filter{"order by", "isnotnull", nil, nil, ""},
+				kendohelper.Filter{"deleted_at", "isnotnull", nil, nil, ""},
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"note", "isempty", "", nil, ""},
+					kendohelper.Filter{"active", "neq", false, nil, ""},
+				}, "and"},
+			}, "or"},
+		},
+		{
+			name:       "not",
+			expression: `not a = 1 and not (b = 2 or c = 3)`,
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"a", "eq", json.Number("1"), nil, ""},
+				}, "not"},
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+						kendohelper.Filter{"b", "eq", json.Number("2"), nil, ""},
+						kendohelper.Filter{"c", "eq", json.Number("3"), nil, ""},
+					}, "or"},
+				}, "not"},
+			}, "and"},
+		},
+		{
+			name:       "in with single value",
+			expression: `client.code in (-1.5e3)`,
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"client.code", "eq", json.Number("-1.5e3"), nil, ""},
+			}, "and"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := kendohelper.ParseFilter(tc.expression)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(filter, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, filter)
+			}
+		})
+	}
+}
+
+func TestParseFilterEmpty(t *testing.T) {
+	tt := []struct {
+		name       string
+		expression string
+		expected   toolkit.M
+	}{
+		{"is empty", "name is empty", toolkit.M{"$and": []toolkit.M{toolkit.M{"name": ""}}}},
+		{"is not empty", "name is not empty", toolkit.M{"$and": []toolkit.M{toolkit.M{"name": toolkit.M{"$ne": ""}}}}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := kendohelper.ParseFilter(tc.expression)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if match := filter.ToAggregateFilter(); !reflect.DeepEqual(match, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, match)
+			}
+		})
+	}
+}
+
+func TestParseFilterError(t *testing.T) {
+	tt := []struct {
+		name       string
+		expression string
+		err        string
+	}{
+		{
+			name:       "missing value",
+			expression: `status = `,
+			err:        "kendohelper: parse error at offset 9: expected value, got end of expression",
+		},
+		{
+			name:       "unknown operator",
+			expression: `status like "x"`,
+			err:        `kendohelper: parse error at offset 7: expected operator, got "like"`,
+		},
+		{
+			name:       "unclosed parenthesis",
+			expression: `(a = 1 or b = 2`,
+			err:        `kendohelper: parse error at offset 15: expected ")", got end of expression`,
+		},
+		{
+			name:       "unterminated string",
+			expression: `a = "x`,
+			err:        "kendohelper: parse error at offset 4: unterminated string",
+		},
+		{
+			name:       "trailing token",
+			expression: `a = 1 b = 2`,
+			err:        `kendohelper: parse error at offset 6: unexpected "b"`,
+		},
+		{
+			name:       "keyword as field",
+			expression: `and = 1`,
+			err:        `kendohelper: parse error at offset 0: expected field, got "and"`,
+		},
+		{
+			name:       "invalid is",
+			expression: `a is 1`,
+			err:        `kendohelper: parse error at offset 5: expected null or empty, got "1"`,
+		},
+		{
+			name:       "invalid number",
+			expression: `a = 1.2.3`,
+			err:        "kendohelper: parse error at offset 4: invalid number 1.2.3",
+		},
+		{
+			name:       "unexpected character",
+			expression: `a = 1 & b = 2`,
+			err:        `kendohelper: parse error at offset 6: unexpected character "&"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := kendohelper.ParseFilter(tc.expression)
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.err, err)
+			}
+		})
+	}
+}
+
+func TestFilterExpression(t *testing.T) {
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected string
+	}{
+		{
+			name: "nested groups and in",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"status", "eq", "open", nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"amount", "gt", 100, nil, ""},
+					kendohelper.Filter{"", "", "", []kendohelper.Filter{
+						kendohelper.Filter{"priority", "eq", "high", nil, ""},
+						kendohelper.Filter{"priority", "eq", "urgent", nil, ""},
+					}, "or"},
+				}, "or"},
+			}, "and"},
+			expected: `status = "open" and (amount > 100 or priority in ("high", "urgent"))`,
+		},
+		{
+			name: "operator names, unary operators, quoted field and date",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "doesnotcontain", "x", nil, ""},
+				kendohelper.Filter{"order by", "isnull", nil, nil, ""},
+				kendohelper.Filter{"created_at", "lt", time.Date(2019, 01, 02, 00, 00, 00, 00, time.UTC), nil, ""},
+				kendohelper.Filter{"Name", "ne", "ignored", nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Age", "lt", 5, nil, ""},
+					kendohelper.Filter{"Age", "gt", 9, nil, ""},
+				}, "not"},
+			}, "and"},
+			expected: "Name doesnotcontain \"x\" and `order by` is null and created_at < \"2019-01-02T00:00:00Z\" and not (Age < 5 and Age > 9)",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expression := tc.filter.Expression()
+			if expression != tc.expected {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, expression)
+			}
+			filter, err := kendohelper.ParseFilter(expression)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if filter.Expression() != expression {
+				t.Errorf("%v should round-trip %v, got %v", tc.name, expression, filter.Expression())
+			}
+		})
+	}
+}
+
+func TestFilterExpressionLocalizedOperators(t *testing.T) {
+	defer func(eq, contains string) {
+		kendohelper.SymbolOperators["eq"] = eq
+		kendohelper.SymbolOperators["contains"] = contains
+	}(kendohelper.SymbolOperators["eq"], kendohelper.SymbolOperators["contains"])
+	kendohelper.SymbolOperators["eq"] = "sama dengan"
+	kendohelper.SymbolOperators["contains"] = "mengandung"
+
+	filter := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"status", "eq", "open", nil, ""},
+		kendohelper.Filter{"Name", "contains", "hari", nil, ""},
+	}, "and"}
+	expected := `status = "open" and Name contains "hari"`
+	expression := filter.Expression()
+	if expression != expected {
+		t.Errorf("expression should be %v, got %v", expected, expression)
+	}
+	parsed, err := kendohelper.ParseFilter(expression)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.Expression() != expected {
+		t.Errorf("parsed expression should be %v, got %v", expected, parsed.Expression())
+	}
+}
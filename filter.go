@@ -8,10 +8,12 @@ package kendohelper
  */
 
 import (
-	"time"
+	"encoding/json"
+	"strings"
 
 	"github.com/eaciit/dbox"
 	"github.com/eaciit/toolkit"
+	"gopkg.in/mgo.v2/bson"
 )
 
 // Filter is Kendo filter's object structure.
@@ -23,6 +25,43 @@ type Filter struct {
 	Logic    string
 }
 
+// negatedOperators maps operators to their exact complement,
+// "lt", "lte", "gt" and "gte" have none since they don't match documents missing the field.
+var negatedOperators = map[string]string{
+	"eq":               "neq",
+	"neq":              "eq",
+	"startswith":       "doesnotstartwith",
+	"doesnotstartwith": "startswith",
+	"endswith":         "doesnotendwith",
+	"doesnotendwith":   "endswith",
+	"contains":         "doesnotcontain",
+	"doesnotcontain":   "contains",
+	"isnull":           "isnotnull",
+	"isnotnull":        "isnull",
+	"isempty":          "isnotempty",
+	"isnotempty":       "isempty",
+}
+
+// filterOperators are the kendo operators understood by the converters.
+var filterOperators = map[string]bool{
+	"eq":               true,
+	"neq":              true,
+	"lt":               true,
+	"lte":              true,
+	"gt":               true,
+	"gte":              true,
+	"startswith":       true,
+	"doesnotstartwith": true,
+	"endswith":         true,
+	"doesnotendwith":   true,
+	"contains":         true,
+	"doesnotcontain":   true,
+	"isnull":           true,
+	"isnotnull":        true,
+	"isempty":          true,
+	"isnotempty":       true,
+}
+
 var defaultDBOXFilter = &dbox.Filter{
 	Field: "_id",
 	Op:    dbox.FilterOpEqual,
@@ -63,11 +102,11 @@ func (f *Filter) HandleField(handler func(field string) string) {
 // Querying a string, except for "eq" and "neq", is case-insensitive
 func (f *Filter) ToDBOXFilter() *dbox.Filter {
 	if len(f.Filters) == 0 {
-		valueStr, ok := f.Value.(string)
+		value := normalizeValue(f.Value)
+		valueStr, ok := value.(string)
 		if ok {
-			t, err := time.Parse(time.RFC3339, valueStr)
-			if err == nil {
-				f.Value = t
+			if t, ok := parseDefaultDate(valueStr); ok {
+				value = t
 			}
 		} else if f.Operator == "startswith" ||
 			f.Operator == "doesnotstartwith" ||
@@ -84,49 +123,28 @@ func (f *Filter) ToDBOXFilter() *dbox.Filter {
 		case "isnotnull":
 			return dbox.Ne(f.Field, nil)
 		case "eq":
-			return dbox.Eq(f.Field, f.Value)
+			return dbox.Eq(f.Field, value)
 		case "neq":
-			return dbox.Ne(f.Field, f.Value)
+			return dbox.Ne(f.Field, value)
 		case "lt":
-			return dbox.Lt(f.Field, f.Value)
+			return dbox.Lt(f.Field, value)
 		case "lte":
-			return dbox.Lte(f.Field, f.Value)
+			return dbox.Lte(f.Field, value)
 		case "gt":
-			return dbox.Gt(f.Field, f.Value)
+			return dbox.Gt(f.Field, value)
 		case "gte":
-			return dbox.Gte(f.Field, f.Value)
+			return dbox.Gte(f.Field, value)
 		case "startswith":
 			return dbox.Startwith(f.Field, valueStr)
-		case "doesnotstartwith":
-			return &dbox.Filter{
-				Field: f.Field,
-				Op:    dbox.FilterOpEqual,
-				Value: toolkit.M{
-					"$regex":   `^(?!` + valueStr + `)\w+`,
-					"$options": "i",
-				},
-			}
 		case "endswith":
 			return dbox.Endwith(f.Field, valueStr)
-		case "doesnotendwith":
-			return &dbox.Filter{
-				Field: f.Field,
-				Op:    dbox.FilterOpEqual,
-				Value: toolkit.M{
-					"$regex":   `.*(?<!` + valueStr + `)$`,
-					"$options": "i",
-				},
-			}
 		case "contains":
 			return dbox.Contains(f.Field, valueStr)
-		case "doesnotcontain":
+		case "doesnotstartwith", "doesnotendwith", "doesnotcontain":
 			return &dbox.Filter{
 				Field: f.Field,
 				Op:    dbox.FilterOpEqual,
-				Value: toolkit.M{
-					"$regex":   `^((?!` + valueStr + `).)*$`,
-					"$options": "i",
-				},
+				Value: toolkit.M{"$not": negatedRegex(f.Operator, valueStr)},
 			}
 		case "isempty":
 			return dbox.Eq(f.Field, "")
@@ -146,6 +164,13 @@ func (f *Filter) ToDBOXFilter() *dbox.Filter {
 	if len(dboxFilters) == 0 {
 		return defaultDBOXFilter
 	}
+	if f.Logic == "not" {
+		// dbox has no negation, push it down to the dbox filters (De Morgan's laws).
+		if len(dboxFilters) == 1 {
+			return negateDBOXFilter(dboxFilters[0])
+		}
+		return negateDBOXFilter(dbox.And(dboxFilters...))
+	}
 	if f.Logic == "and" {
 		return dbox.And(dboxFilters...)
 	} else if f.Logic == "or" {
@@ -158,11 +183,11 @@ func (f *Filter) ToDBOXFilter() *dbox.Filter {
 // Querying a string, except for "eq" and "neq", is case-insensitive
 func (f *Filter) ToAggregateFilter() toolkit.M {
 	if len(f.Filters) == 0 {
-		valueStr, ok := f.Value.(string)
+		value := normalizeValue(f.Value)
+		valueStr, ok := value.(string)
 		if ok {
-			t, err := time.Parse(time.RFC3339, valueStr)
-			if err == nil {
-				f.Value = t
+			if t, ok := parseDefaultDate(valueStr); ok {
+				value = t
 			}
 		} else if f.Operator == "startswith" ||
 			f.Operator == "doesnotstartwith" ||
@@ -179,9 +204,9 @@ func (f *Filter) ToAggregateFilter() toolkit.M {
 		case "isnotnull":
 			return toolkit.M{f.Field: toolkit.M{"$ne": nil}}
 		case "eq":
-			return toolkit.M{f.Field: f.Value}
+			return toolkit.M{f.Field: value}
 		case "neq":
-			return toolkit.M{f.Field: toolkit.M{"$ne": f.Value}}
+			return toolkit.M{f.Field: toolkit.M{"$ne": value}}
 		case "lt":
 			fallthrough
 		case "lte":
@@ -190,38 +215,25 @@ func (f *Filter) ToAggregateFilter() toolkit.M {
 			fallthrough
 		case "gte":
 			return toolkit.M{f.Field: toolkit.M{
-				"$" + f.Operator: f.Value,
+				"$" + f.Operator: value,
 			}}
 		case "startswith":
 			return toolkit.M{f.Field: toolkit.M{
 				"$regex":   `^` + valueStr,
 				"$options": "i",
 			}}
-		case "doesnotstartwith":
-			return toolkit.M{f.Field: toolkit.M{
-				"$regex":   `^(?!` + valueStr + `)\w+`,
-				"$options": "i",
-			}}
 		case "endswith":
 			return toolkit.M{f.Field: toolkit.M{
 				"$regex":   valueStr + `$`,
 				"$options": "i",
 			}}
-		case "doesnotendwith":
-			return toolkit.M{f.Field: toolkit.M{
-				"$regex":   `.*(?<!` + valueStr + `)$`,
-				"$options": "i",
-			}}
 		case "contains":
 			return toolkit.M{f.Field: toolkit.M{
 				"$regex":   `.*` + valueStr + `.*`,
 				"$options": "i",
 			}}
-		case "doesnotcontain":
-			return toolkit.M{f.Field: toolkit.M{
-				"$regex":   `^((?!` + valueStr + `).)*$`,
-				"$options": "i",
-			}}
+		case "doesnotstartwith", "doesnotendwith", "doesnotcontain":
+			return toolkit.M{f.Field: toolkit.M{"$not": negatedRegex(f.Operator, valueStr)}}
 		case "isempty":
 			return toolkit.M{f.Field: ""}
 		case "isnotempty":
@@ -240,6 +252,12 @@ func (f *Filter) ToAggregateFilter() toolkit.M {
 	if len(matches) == 0 {
 		return nil
 	}
+	if f.Logic == "not" {
+		if len(matches) == 1 {
+			return toolkit.M{"$nor": matches}
+		}
+		return toolkit.M{"$nor": []toolkit.M{toolkit.M{"$and": matches}}}
+	}
 	if f.Logic == "and" {
 		return toolkit.M{"$and": matches}
 	} else if f.Logic == "or" {
@@ -282,3 +300,192 @@ func (f *Filter) HasField(fields ...string) bool {
 	}
 	return false
 }
+
+// normalizeValue converts values decoded by UnmarshalJSON into the types expected by the database.
+// json.Number becomes int64 when it's an integer, otherwise float64.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = normalizeValue(v[i])
+		}
+		return values
+	}
+	return value
+}
+
+// And combines filters with "and" logic into a brand new Filter, empty filters are skipped.
+// Nested "and" filters are flattened, the result never shares its Filters with the given filters.
+func And(filters ...Filter) Filter {
+	return combine("and", filters)
+}
+
+// Or combines filters with "or" logic into a brand new Filter, empty filters are skipped.
+// Nested "or" filters are flattened, the result never shares its Filters with the given filters.
+func Or(filters ...Filter) Filter {
+	return combine("or", filters)
+}
+
+// Not negates filter into a brand new Filter having "not" logic, it matches when filter doesn't match.
+// Negating a negation gives back the original filter.
+func Not(filter Filter) Filter {
+	if filter.isEmpty() {
+		return Filter{}
+	}
+	if filter.Logic == "not" && len(filter.Filters) == 1 {
+		return filter.Filters[0].DeepClone()
+	}
+	return Filter{Filters: []Filter{filter.DeepClone()}, Logic: "not"}
+}
+
+// Invert returns a brand new Filter matching exactly what f doesn't match, pushing the negation down
+// to the operators using De Morgan's laws: "and" becomes "or" and vice versa, "eq" becomes "neq", "contains" becomes "doesnotcontain", etc.
+// "lt", "lte", "gt" and "gte" are wrapped with Not instead, since e.g. "gte" doesn't match documents missing the field while negated "lt" does.
+func (f *Filter) Invert() Filter {
+	if len(f.Filters) == 0 {
+		if operator, ok := negatedOperators[f.Operator]; ok {
+			filter := f.DeepClone()
+			filter.Operator = operator
+			return filter
+		}
+		return Not(*f)
+	}
+	switch f.Logic {
+	case "not":
+		return And(f.Filters...)
+	case "and", "or":
+		filters := make([]Filter, 0, len(f.Filters))
+		for i := range f.Filters {
+			filters = append(filters, f.Filters[i].Invert())
+		}
+		if f.Logic == "and" {
+			return Or(filters...)
+		}
+		return And(filters...)
+	}
+	return Not(*f)
+}
+
+func combine(logic string, filters []Filter) Filter {
+	result := Filter{Logic: logic}
+	for i := range filters {
+		if filters[i].isEmpty() {
+			continue
+		}
+		filter := filters[i].DeepClone()
+		if len(filter.Filters) != 0 && filter.Logic == logic {
+			result.Filters = append(result.Filters, filter.Filters...)
+			continue
+		}
+		result.Filters = append(result.Filters, filter)
+	}
+	if len(result.Filters) == 0 {
+		return Filter{}
+	}
+	return result
+}
+
+// isEmpty checks whether filter has no operator at all, including its nested filters.
+func (f *Filter) isEmpty() bool {
+	if len(f.Filters) == 0 {
+		return f.Operator == ""
+	}
+	for i := range f.Filters {
+		if !f.Filters[i].isEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// dboxComparisons maps dbox comparison operators to mongo's.
+var dboxComparisons = map[dbox.FilterOp]string{
+	dbox.FilterOpLt:  "$lt",
+	dbox.FilterOpLte: "$lte",
+	dbox.FilterOpGt:  "$gt",
+	dbox.FilterOpGte: "$gte",
+}
+
+// negateDBOXFilter returns the complement of filter built by ToDBOXFilter: "and" becomes "or" and vice versa,
+// "eq" becomes "ne" and vice versa, while comparisons and regexes are wrapped with $not,
+// since e.g. {$not: {$gte: 25}} matches documents missing the field while {$lt: 25} doesn't.
+func negateDBOXFilter(filter *dbox.Filter) *dbox.Filter {
+	switch filter.Op {
+	case dbox.FilterOpAnd, dbox.FilterOpOr:
+		filters := filter.Value.([]*dbox.Filter)
+		negated := make([]*dbox.Filter, len(filters))
+		for i := range filters {
+			negated[i] = negateDBOXFilter(filters[i])
+		}
+		if filter.Op == dbox.FilterOpAnd {
+			return dbox.Or(negated...)
+		}
+		return dbox.And(negated...)
+	case dbox.FilterOpEqual:
+		if m, ok := filter.Value.(toolkit.M); ok && isOperatorExpression(m) {
+			if regex, ok := m["$not"]; ok && len(m) == 1 {
+				return dbox.Eq(filter.Field, regex)
+			}
+			return dbox.Eq(filter.Field, toolkit.M{"$not": m})
+		}
+		if regex, ok := filter.Value.(bson.RegEx); ok {
+			// $ne doesn't accept a regex
+			return dbox.Eq(filter.Field, toolkit.M{"$not": regex})
+		}
+		return dbox.Ne(filter.Field, filter.Value)
+	case dbox.FilterOpNoEqual:
+		return dbox.Eq(filter.Field, filter.Value)
+	case dbox.FilterOpStartWith:
+		return dbox.Eq(filter.Field, toolkit.M{"$not": negatedRegex("doesnotstartwith", filter.Value.(string))})
+	case dbox.FilterOpEndWith:
+		return dbox.Eq(filter.Field, toolkit.M{"$not": negatedRegex("doesnotendwith", filter.Value.(string))})
+	case dbox.FilterOpContains:
+		values := filter.Value.([]string)
+		negated := make([]*dbox.Filter, len(values))
+		for i := range values {
+			negated[i] = dbox.Eq(filter.Field, toolkit.M{"$not": negatedRegex("doesnotcontain", values[i])})
+		}
+		if len(negated) == 1 {
+			return negated[0]
+		}
+		return dbox.And(negated...)
+	}
+	operator, ok := dboxComparisons[filter.Op]
+	if !ok {
+		operator = string(filter.Op)
+	}
+	return dbox.Eq(filter.Field, toolkit.M{"$not": toolkit.M{operator: filter.Value}})
+}
+
+// isOperatorExpression checks whether m is mongo's operator expression, e.g. {$exists: true}, rather than a document.
+func isOperatorExpression(m toolkit.M) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// negatedRegex returns the regex of the positive form of doesnot* operators, to be used in $not.
+func negatedRegex(operator, value string) bson.RegEx {
+	switch operator {
+	case "doesnotstartwith":
+		return bson.RegEx{Pattern: `^` + value, Options: "i"}
+	case "doesnotendwith":
+		return bson.RegEx{Pattern: value + `$`, Options: "i"}
+	}
+	return bson.RegEx{Pattern: `.*` + value + `.*`, Options: "i"}
+}
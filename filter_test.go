@@ -9,6 +9,7 @@ import (
 	"github.com/eaciit/dbox"
 	"github.com/eaciit/toolkit"
 	"github.com/muktihari/kendohelper"
+	"gopkg.in/mgo.v2/bson"
 )
 
 func TestFilterHandleField(t *testing.T) {
@@ -244,10 +245,18 @@ func TestToDBOXFilter(t *testing.T) {
 			expected: dbox.And(&dbox.Filter{
 				Field: "Name",
 				Op:    dbox.FilterOpEqual,
-				Value: toolkit.M{
-					"$regex":   `^(?!H)\w+`,
-					"$options": "i",
-				},
+				Value: toolkit.M{"$not": bson.RegEx{Pattern: `^H`, Options: "i"}},
+			}),
+		},
+		{
+			name: "doesnotendwith",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "doesnotendwith", "H", nil, ""},
+			}, "and"},
+			expected: dbox.And(&dbox.Filter{
+				Field: "Name",
+				Op:    dbox.FilterOpEqual,
+				Value: toolkit.M{"$not": bson.RegEx{Pattern: `H$`, Options: "i"}},
 			}),
 		},
 		{
@@ -265,12 +274,64 @@ func TestToDBOXFilter(t *testing.T) {
 			expected: dbox.And(&dbox.Filter{
 				Field: "Name",
 				Op:    dbox.FilterOpEqual,
-				Value: toolkit.M{
-					"$regex":   `^((?!H).)*$`,
-					"$options": "i",
-				},
+				Value: toolkit.M{"$not": bson.RegEx{Pattern: `.*H.*`, Options: "i"}},
 			}),
 		},
+		{
+			name: "not",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Age", "lt", 25, nil, ""},
+					kendohelper.Filter{"Name", "ne", "Hari", nil, ""},
+				}, "not"},
+			}, "and"},
+			expected: dbox.And(dbox.Eq("Age", toolkit.M{"$not": toolkit.M{"$lt": 25}})),
+		},
+		{
+			name: "not pushed down",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"", "", "", []kendohelper.Filter{
+						kendohelper.Filter{"Name", "contains", "H", nil, ""},
+						kendohelper.Filter{"Name", "doesnotstartwith", "A", nil, ""},
+						kendohelper.Filter{"Deleted", "isnull", nil, nil, ""},
+					}, "or"},
+					kendohelper.Filter{"tenant_id", "neq", "t1", nil, ""},
+					kendohelper.Filter{"Kind", "eq", "a", nil, ""},
+				}, "not"},
+			}, "and"},
+			expected: dbox.And(dbox.Or(
+				dbox.And(
+					dbox.Eq("Name", toolkit.M{"$not": bson.RegEx{Pattern: `.*H.*`, Options: "i"}}),
+					dbox.Eq("Name", bson.RegEx{Pattern: `^A`, Options: "i"}),
+					dbox.Ne("Deleted", nil),
+				),
+				dbox.Eq("tenant_id", "t1"),
+				dbox.Ne("Kind", "a"),
+			)),
+		},
+		{
+			name: "not of not",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"", "", "", []kendohelper.Filter{
+						kendohelper.Filter{"Age", "gte", 25, nil, ""},
+					}, "not"},
+				}, "not"},
+			}, "and"},
+			expected: dbox.And(dbox.Eq("Age", toolkit.M{"$gte": 25})),
+		},
+		{
+			name: "not of not doesnotstartwith",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"", "", "", []kendohelper.Filter{
+						kendohelper.Filter{"Name", "doesnotstartwith", "a", nil, ""},
+					}, "not"},
+				}, "not"},
+			}, "and"},
+			expected: dbox.And(dbox.Eq("Name", toolkit.M{"$not": bson.RegEx{Pattern: `^a`, Options: "i"}})),
+		},
 		{
 			name: "isempty",
 			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
@@ -285,6 +346,11 @@ func TestToDBOXFilter(t *testing.T) {
 			}, "and"},
 			expected: dbox.And(dbox.Ne("Name", "")),
 		},
+		{
+			name:     "leaf",
+			filter:   kendohelper.Filter{"created_at", "gte", "2019-01-01T00:00:00Z", nil, ""},
+			expected: dbox.Gte("created_at", time.Date(2019, 01, 01, 00, 00, 00, 00, time.UTC)),
+		},
 		{
 			name: "working with date",
 			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
@@ -304,10 +370,14 @@ func TestToDBOXFilter(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
+			before := tc.filter.DeepClone()
 			dboxFilter := tc.filter.ToDBOXFilter()
 			if !reflect.DeepEqual(dboxFilter, tc.expected) {
 				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, dboxFilter)
 			}
+			if !reflect.DeepEqual(tc.filter, before) {
+				t.Errorf("%v should not modify the filter, got %v", tc.name, tc.filter)
+			}
 		})
 	}
 }
@@ -440,10 +510,16 @@ func TestToAggregateFilter(t *testing.T) {
 				kendohelper.Filter{"Name", "doesnotstartwith", "H", nil, ""},
 			}, "and"},
 			expected: toolkit.M{"$and": []toolkit.M{
-				toolkit.M{"Name": toolkit.M{
-					"$regex":   `^(?!H)\w+`,
-					"$options": "i",
-				}},
+				toolkit.M{"Name": toolkit.M{"$not": bson.RegEx{Pattern: `^H`, Options: "i"}}},
+			}},
+		},
+		{
+			name: "doesnotendwith",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "doesnotendwith", "H", nil, ""},
+			}, "and"},
+			expected: toolkit.M{"$and": []toolkit.M{
+				toolkit.M{"Name": toolkit.M{"$not": bson.RegEx{Pattern: `H$`, Options: "i"}}},
 			}},
 		},
 		{
@@ -464,9 +540,29 @@ func TestToAggregateFilter(t *testing.T) {
 				kendohelper.Filter{"Name", "doesnotcontain", "H", nil, ""},
 			}, "and"},
 			expected: toolkit.M{"$and": []toolkit.M{
-				toolkit.M{"Name": toolkit.M{
-					"$regex":   `^((?!H).)*$`,
-					"$options": "i",
+				toolkit.M{"Name": toolkit.M{"$not": bson.RegEx{Pattern: `.*H.*`, Options: "i"}}},
+			}},
+		},
+		{
+			name: "not",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Age", "lt", 25, nil, ""},
+				}, "not"},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Age", "gt", 30, nil, ""},
+					kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+				}, "not"},
+			}, "and"},
+			expected: toolkit.M{"$and": []toolkit.M{
+				toolkit.M{"$nor": []toolkit.M{
+					toolkit.M{"Age": toolkit.M{"$lt": 25}},
+				}},
+				toolkit.M{"$nor": []toolkit.M{
+					toolkit.M{"$and": []toolkit.M{
+						toolkit.M{"Age": toolkit.M{"$gt": 30}},
+						toolkit.M{"Name": "Hari"},
+					}},
 				}},
 			}},
 		},
@@ -488,6 +584,11 @@ func TestToAggregateFilter(t *testing.T) {
 				toolkit.M{"Name": toolkit.M{"$ne": ""}},
 			}},
 		},
+		{
+			name:     "leaf",
+			filter:   kendohelper.Filter{"created_at", "gte", "2019-01-01T00:00:00Z", nil, ""},
+			expected: toolkit.M{"created_at": toolkit.M{"$gte": time.Date(2019, 01, 01, 00, 00, 00, 00, time.UTC)}},
+		},
 		{
 			name: "working with date",
 			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
@@ -511,10 +612,14 @@ func TestToAggregateFilter(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
+			before := tc.filter.DeepClone()
 			aggrFilter := tc.filter.ToAggregateFilter()
 			if !reflect.DeepEqual(aggrFilter, tc.expected) {
 				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, aggrFilter)
 			}
+			if !reflect.DeepEqual(tc.filter, before) {
+				t.Errorf("%v should not modify the filter, got %v", tc.name, tc.filter)
+			}
 		})
 	}
 }
@@ -809,3 +914,135 @@ func TestFilterHasField(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterCompose(t *testing.T) {
+	userFilter := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"Name", "contains", "hari", nil, ""},
+		kendohelper.Filter{"Age", "gte", 25, nil, ""},
+	}, "or"}
+	tenantFilter := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"tenant_id", "eq", "t1", nil, ""},
+	}, "and"}
+
+	tt := []struct {
+		name     string
+		result   kendohelper.Filter
+		expected kendohelper.Filter
+	}{
+		{
+			name:   "and skips empty filters and flattens and",
+			result: kendohelper.And(userFilter, kendohelper.Filter{}, kendohelper.Filter{"", "", "", []kendohelper.Filter{}, "and"}, tenantFilter),
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Name", "contains", "hari", nil, ""},
+					kendohelper.Filter{"Age", "gte", 25, nil, ""},
+				}, "or"},
+				kendohelper.Filter{"tenant_id", "eq", "t1", nil, ""},
+			}, "and"},
+		},
+		{
+			name:   "or flattens or",
+			result: kendohelper.Or(userFilter, kendohelper.Filter{"deleted", "eq", false, nil, ""}),
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Name", "contains", "hari", nil, ""},
+				kendohelper.Filter{"Age", "gte", 25, nil, ""},
+				kendohelper.Filter{"deleted", "eq", false, nil, ""},
+			}, "or"},
+		},
+		{
+			name:     "all empty",
+			result:   kendohelper.And(kendohelper.Filter{}, kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{}}, "or"}),
+			expected: kendohelper.Filter{},
+		},
+		{
+			name:   "not",
+			result: kendohelper.Not(tenantFilter),
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"tenant_id", "eq", "t1", nil, ""},
+				}, "and"},
+			}, "not"},
+		},
+		{
+			name:     "not of not",
+			result:   kendohelper.Not(kendohelper.Not(tenantFilter)),
+			expected: tenantFilter,
+		},
+		{
+			name:     "not empty",
+			result:   kendohelper.Not(kendohelper.Filter{}),
+			expected: kendohelper.Filter{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tc.result, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, tc.result)
+			}
+		})
+	}
+
+	result := kendohelper.And(userFilter, tenantFilter)
+	result.HandleField(strings.ToUpper)
+	if userFilter.Filters[0].Field != "Name" || tenantFilter.Filters[0].Field != "tenant_id" {
+		t.Errorf("and should not share filters with its operands, got %v and %v", userFilter, tenantFilter)
+	}
+}
+
+func TestFilterInvert(t *testing.T) {
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected kendohelper.Filter
+	}{
+		{
+			name: "de morgan",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Name", "contains", "hari", nil, ""},
+					kendohelper.Filter{"Deleted", "isnull", nil, nil, ""},
+				}, "or"},
+				kendohelper.Filter{"tenant_id", "eq", "t1", nil, ""},
+			}, "and"},
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"Name", "doesnotcontain", "hari", nil, ""},
+					kendohelper.Filter{"Deleted", "isnotnull", nil, nil, ""},
+				}, "and"},
+				kendohelper.Filter{"tenant_id", "neq", "t1", nil, ""},
+			}, "or"},
+		},
+		{
+			name: "comparison is wrapped with not",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Age", "gte", 25, nil, ""},
+				kendohelper.Filter{"Name", "doesnotstartwith", "h", nil, ""},
+			}, "or"},
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"Age", "gte", 25, nil, ""},
+				}, "not"},
+				kendohelper.Filter{"Name", "startswith", "h", nil, ""},
+			}, "and"},
+		},
+		{
+			name: "not is removed",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Age", "gte", 25, nil, ""},
+			}, "not"},
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Age", "gte", 25, nil, ""},
+			}, "and"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.filter.Invert()
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.telerik.com/kendo-ui/api/javascript/data/datasource/configuration/transport.read
+ * https://docs.telerik.com/kendo-ui/api/javascript/data/transport/parametermap
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRequestBytes is the maximum size of the JSON body accepted by DecodeRequest.
+var MaxRequestBytes int64 = 1 << 20
+
+// DataFunc queries the data of a decoded, validated and aliased request, e.g. using ToAggregatePipeline and NewResponse.
+type DataFunc func(ctx context.Context, request Request) (Response, error)
+
+// Handler is http.Handler serving a grid endpoint: it decodes the request, validates and aliases its fields,
+// calls Data and writes the Response as JSON. Invalid requests get 400 with the reason in errors.
+type Handler struct {
+	// Fields maps the fields which can be filtered, sorted, grouped and aggregated to their database fields,
+	// e.g. {"name": "fullname", "age": "age"}. Nil allows any field as is.
+	Fields map[string]string
+	// Policy limits the cost of the filter and sort of requests as they're queried: after aliasing, Lookups and ColumnPolicy,
+	// before RowPolicy whose rules aren't the user's. It may be nil.
+	Policy *Policy
+	// Lookups translates the filters on display fields of foreign-key columns into their keys, after aliasing. It may be nil.
+	// Requests whose lookup fails get 500.
+	Lookups Lookups
+	// Dates parses the date values of the filter of requests into time.Time, after Lookups, so Data needs no DateParser. It may be nil.
+	Dates *DateParser
+	// Location returns the location of the user (e.g. LocationFromHeader("X-Time-Zone") or from the user's profile), used by Dates
+	// to parse dates having no zone. Nil or returning nil falls back to the location of the request's context (see WithLocation), then Dates' Location.
+	Location func(r *http.Request) *time.Location
+	// Schema converts the filter values of typed fields (e.g. decimal, int64) exactly, after aliasing. It may be nil.
+	Schema Schema
+	// ColumnPolicy rewrites or rejects the filters and sort elements of requests by the roles of the request's context (see WithRoles),
+	// after aliasing. It may be nil.
+	ColumnPolicy *ColumnPolicy
+	// Audit is called with what's changed or rejected by ColumnPolicy. It may be nil.
+	Audit func(r *http.Request, audit []ColumnAudit)
+	// RowPolicy constrains the filter of requests by the params of the request's context (see WithParams), after aliasing.
+	// It may be nil. Requests it can't be applied to get 403.
+	RowPolicy *RowPolicy
+	// Data queries the data, an error other than *DecodeError, *ParseError, *PolicyError or *ColumnPolicyError results in 500.
+	Data DataFunc
+	// ErrorLog is called with errors resulting in 500, which are not sent to the client. It may be nil.
+	ErrorLog func(r *http.Request, err error)
+}
+
+type requestContextKey struct{}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request, err := h.Decode(r)
+	if err != nil {
+		h.writeDecodeError(w, r, err)
+		return
+	}
+	response, err := h.Data(r.Context(), request)
+	if err != nil {
+		switch err.(type) {
+		case *DecodeError, *ParseError, *PolicyError, *ColumnPolicyError:
+			WriteResponse(w, http.StatusBadRequest, NewErrorResponse(err))
+			return
+		}
+		if h.ErrorLog != nil {
+			h.ErrorLog(r, err)
+		}
+		WriteResponse(w, http.StatusInternalServerError, NewErrorResponse(errors.New(http.StatusText(http.StatusInternalServerError))))
+		return
+	}
+	WriteResponse(w, http.StatusOK, response)
+}
+
+// Middleware decodes, validates and aliases the request the way ServeHTTP does and passes it to next through the context,
+// use RequestFromContext to get it. Invalid requests get 400 without calling next.
+func (h *Handler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := h.Decode(r)
+		if err != nil {
+			h.writeDecodeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestContextKey{}, request)))
+	})
+}
+
+// writeDecodeError writes 403 for *RowPolicyError and 500 for *LookupError, which aren't sent to the client, or else 400.
+func (h *Handler) writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadRequest
+	switch err.(type) {
+	case *RowPolicyError:
+		status = http.StatusForbidden
+	case *LookupError:
+		status = http.StatusInternalServerError
+	default:
+		WriteResponse(w, status, NewErrorResponse(err))
+		return
+	}
+	if h.ErrorLog != nil {
+		h.ErrorLog(r, err)
+	}
+	WriteResponse(w, status, NewErrorResponse(errors.New(http.StatusText(status))))
+}
+
+// RequestFromContext returns the request decoded by Middleware.
+func RequestFromContext(ctx context.Context) (Request, bool) {
+	request, ok := ctx.Value(requestContextKey{}).(Request)
+	return request, ok
+}
+
+// Decode decodes the request with DecodeRequest, rejects fields not listed in Fields and aliases the rest,
+// then translates Lookups, parses Dates, converts the filter values by Schema, applies ColumnPolicy,
+// checks the result against Policy and applies RowPolicy.
+func (h *Handler) Decode(r *http.Request) (Request, error) {
+	request, err := DecodeRequest(r)
+	if err != nil {
+		return request, err
+	}
+	if h.Fields != nil {
+		if err := h.alias(&request); err != nil {
+			return Request{}, err
+		}
+	}
+	if h.Lookups != nil {
+		if request.Filter, err = h.Lookups.Translate(r.Context(), request.Filter); err != nil {
+			return Request{}, err
+		}
+	}
+	if h.Dates != nil {
+		request.Filter = h.dates(r).Convert(request.Filter)
+	}
+	if h.Schema != nil {
+		if request.Filter, err = h.Schema.Convert(request.Filter); err != nil {
+			return Request{}, err
+		}
+	}
+	if h.ColumnPolicy != nil {
+		audit, err := h.ColumnPolicy.Apply(r.Context(), &request)
+		if len(audit) != 0 && h.Audit != nil {
+			h.Audit(r, audit)
+		}
+		if err != nil {
+			return Request{}, err
+		}
+	}
+	if h.Policy != nil {
+		if err := h.Policy.Check(request); err != nil {
+			return Request{}, err
+		}
+	}
+	if h.RowPolicy != nil {
+		if err := h.RowPolicy.ApplyRequest(r.Context(), &request); err != nil {
+			return Request{}, err
+		}
+	}
+	return request, nil
+}
+
+// dates returns Dates in the location of the user.
+func (h *Handler) dates(r *http.Request) *DateParser {
+	if h.Location != nil {
+		if location := h.Location(r); location != nil {
+			return h.Dates.In(location)
+		}
+	}
+	if location, ok := LocationFromContext(r.Context()); ok {
+		return h.Dates.In(location)
+	}
+	return h.Dates
+}
+
+func (h *Handler) alias(request *Request) error {
+	var invalid string
+	alias := func(field string) string {
+		dbField, ok := h.Fields[field]
+		if !ok && invalid == "" {
+			invalid = field
+		}
+		return dbField
+	}
+	request.Filter.Handle(func(filter Filter) Filter {
+		if filter.Operator != "" {
+			filter.Field = alias(filter.Field)
+		}
+		return filter
+	})
+	request.Sort.HandleField(alias)
+	for i := range request.Group {
+		request.Group[i].Field = alias(request.Group[i].Field)
+		for j := range request.Group[i].Aggregates {
+			request.Group[i].Aggregates[j].Field = alias(request.Group[i].Aggregates[j].Field)
+		}
+	}
+	for i := range request.Aggregate {
+		request.Aggregate[i].Field = alias(request.Aggregate[i].Field)
+	}
+	if invalid != "" {
+		return &DecodeError{"request", "field " + strconv.Quote(invalid) + " is not allowed"}
+	}
+	return nil
+}
+
+// DecodeRequest decodes kendo DataSource's request from the JSON body (contentType "application/json")
+// or else from the query string (or form), either in jQuery's bracket notation (kendo's default):
+//
+//	take=10&skip=0&sort[0][field]=Name&sort[0][dir]=asc&filter[logic]=and&filter[filters][0][field]=Name&...
+//
+// or having filter, sort, group and aggregate as JSON (parameterMap using kendo.stringify).
+// Filter and Sort are decoded strictly, see their UnmarshalJSON. Values in the query string are strings.
+func DecodeRequest(r *http.Request) (Request, error) {
+	request := Request{}
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType == "application/json" && r.Body != nil {
+		data, err := io.ReadAll(io.LimitReader(r.Body, MaxRequestBytes+1))
+		if err != nil {
+			return request, &DecodeError{"request", "cannot be read"}
+		}
+		if int64(len(data)) > MaxRequestBytes {
+			return request, &DecodeError{"request", "exceeds maximum size of " + strconv.FormatInt(MaxRequestBytes, 10) + " bytes"}
+		}
+		return request, decodeRequestJSON(data, &request)
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return request, &DecodeError{"request", "malformed query string"}
+	}
+	data, err := json.Marshal(queryTree(r.Form))
+	if err != nil {
+		return request, &DecodeError{"request", "malformed query string"}
+	}
+	return request, decodeRequestJSON(data, &request)
+}
+
+func decodeRequestJSON(data []byte, request *Request) error {
+	if err := json.Unmarshal(data, request); err != nil {
+		if _, ok := err.(*DecodeError); ok {
+			return err
+		}
+		if err, ok := err.(*json.UnmarshalTypeError); ok && err.Field != "" {
+			return &DecodeError{err.Field, "must be " + err.Type.String()}
+		}
+		return &DecodeError{"request", "must be a JSON object"}
+	}
+	return nil
+}
+
+// queryTree builds JSON-like value from the query string in bracket notation,
+// objects having only numeric keys become arrays ordered by their keys.
+func queryTree(values url.Values) interface{} {
+	tree := map[string]interface{}{}
+	for key, v := range values {
+		if len(v) == 0 {
+			continue
+		}
+		path := strings.Split(strings.Replace(key, "]", "", -1), "[")
+		value := interface{}(v[0])
+		if len(path) == 1 {
+			var raw json.RawMessage
+			if json.Unmarshal([]byte(v[0]), &raw) == nil && (strings.HasPrefix(v[0], "{") || strings.HasPrefix(v[0], "[")) {
+				value = raw
+			}
+		}
+		switch path[len(path)-1] {
+		case "take", "skip", "page", "pageSize":
+			if i, err := strconv.Atoi(v[0]); err == nil && len(path) == 1 {
+				value = i
+			}
+		case "ignoreCase":
+			if b, err := strconv.ParseBool(v[0]); err == nil {
+				value = b
+			}
+		}
+
+		node := tree
+		for i, name := range path {
+			if i == len(path)-1 {
+				node[name] = value
+				break
+			}
+			child, ok := node[name].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[name] = child
+			}
+			node = child
+		}
+	}
+	return arrays(tree)
+}
+
+func arrays(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	keys := make([]string, 0, len(m))
+	indexes := map[string]int{}
+	for key := range m {
+		m[key] = arrays(m[key])
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 {
+			keys = append(keys, key)
+			indexes[key] = i
+		}
+	}
+	if len(m) == 0 || len(keys) != len(m) {
+		return m
+	}
+	sort.Slice(keys, func(i, j int) bool { return indexes[keys[i]] < indexes[keys[j]] })
+	array := make([]interface{}, len(keys))
+	for i, key := range keys {
+		array[i] = m[key]
+	}
+	return array
+}
+
+// WriteResponse writes response as JSON with status.
+func WriteResponse(w http.ResponseWriter, status int, response Response) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
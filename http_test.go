@@ -0,0 +1,236 @@
+package kendohelper_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	expected := kendohelper.Request{
+		Take:     10,
+		Skip:     20,
+		Page:     3,
+		PageSize: 10,
+		Sort:     kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}, kendohelper.SortElem{"Age", "desc"}},
+		Filter: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{"Name", "contains", "hari", nil, ""},
+			kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"City", "eq", "Jakarta", nil, ""},
+				kendohelper.Filter{"City", "eq", "Bandung", nil, ""},
+			}, "or"},
+		}, "and"},
+		Group:     []kendohelper.GroupElem{kendohelper.GroupElem{"City", "asc", nil}},
+		Aggregate: []kendohelper.AggregateElem{kendohelper.AggregateElem{"Age", "sum"}},
+	}
+	bracket := url.Values{
+		"take":                                  {"10"},
+		"skip":                                  {"20"},
+		"page":                                  {"3"},
+		"pageSize":                              {"10"},
+		"sort[0][field]":                        {"Name"},
+		"sort[0][dir]":                          {"asc"},
+		"sort[1][field]":                        {"Age"},
+		"sort[1][dir]":                          {"desc"},
+		"filter[logic]":                         {"and"},
+		"filter[filters][0][field]":             {"Name"},
+		"filter[filters][0][operator]":          {"contains"},
+		"filter[filters][0][value]":             {"hari"},
+		"filter[filters][0][ignoreCase]":        {"true"},
+		"filter[filters][1][logic]":             {"or"},
+		"filter[filters][1][filters][0][field]": {"City"},
+		"filter[filters][1][filters][0][operator]":  {"eq"},
+		"filter[filters][1][filters][0][value]":     {"Jakarta"},
+		"filter[filters][1][filters][10][field]":    {"City"},
+		"filter[filters][1][filters][10][operator]": {"eq"},
+		"filter[filters][1][filters][10][value]":    {"Bandung"},
+		"group[0][field]":                           {"City"},
+		"group[0][dir]":                             {"asc"},
+		"aggregate[0][field]":                       {"Age"},
+		"aggregate[0][aggregate]":                   {"sum"},
+	}
+	stringified := url.Values{
+		"take":      {"10"},
+		"skip":      {"20"},
+		"page":      {"3"},
+		"pageSize":  {"10"},
+		"sort":      {`[{"field":"Name","dir":"asc"},{"field":"Age","dir":"desc"}]`},
+		"filter":    {`{"logic":"and","filters":[{"field":"Name","operator":"contains","value":"hari"},{"logic":"or","filters":[{"field":"City","operator":"eq","value":"Jakarta"},{"field":"City","operator":"eq","value":"Bandung"}]}]}`},
+		"group":     {`[{"field":"City","dir":"asc"}]`},
+		"aggregate": {`[{"field":"Age","aggregate":"sum"}]`},
+	}
+	body := `{"take":10,"skip":20,"page":3,"pageSize":10,"sort":` + stringified.Get("sort") + `,"filter":` + stringified.Get("filter") +
+		`,"group":` + stringified.Get("group") + `,"aggregate":` + stringified.Get("aggregate") + `}`
+
+	tt := []struct {
+		name    string
+		request *http.Request
+	}{
+		{"query string in bracket notation", httptest.NewRequest("GET", "/grid?"+bracket.Encode(), nil)},
+		{"query string having JSON", httptest.NewRequest("GET", "/grid?"+stringified.Encode(), nil)},
+		{"form", newFormRequest(bracket)},
+		{"JSON body", newJSONRequest(body)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			request, err := kendohelper.DecodeRequest(tc.request)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(request, expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, expected, request)
+			}
+		})
+	}
+}
+
+func TestDecodeRequestError(t *testing.T) {
+	tt := []struct {
+		name    string
+		request *http.Request
+		err     string
+	}{
+		{
+			name:    "unknown filter key",
+			request: newJSONRequest(`{"filter":{"field":"Name","operator":"eq","$where":"1"}}`),
+			err:     `kendohelper: filter: unknown key "$where"`,
+		},
+		{
+			name:    "invalid take",
+			request: httptest.NewRequest("GET", "/grid?take=ten", nil),
+			err:     "kendohelper: take: must be int",
+		},
+		{
+			name:    "not an object",
+			request: newJSONRequest(`[]`),
+			err:     "kendohelper: request: must be a JSON object",
+		},
+		{
+			name:    "unsupported dir",
+			request: httptest.NewRequest("GET", "/grid?sort[0][field]=Name&sort[0][dir]=up", nil),
+			err:     `kendohelper: sort[0]: unsupported dir "up"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := kendohelper.DecodeRequest(tc.request)
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.err, err)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var logged error
+	handler := &kendohelper.Handler{
+		Fields: map[string]string{"name": "fullname", "age": "age"},
+		Data: func(ctx context.Context, request kendohelper.Request) (kendohelper.Response, error) {
+			if request.Take == 99 {
+				return kendohelper.Response{}, errors.New("connection refused")
+			}
+			return kendohelper.Response{
+				Data:  []toolkit.M{toolkit.M{"filter": request.Filter.Expression(), "sort": request.Sort.Describe(nil)}},
+				Total: 1,
+			}, nil
+		},
+		ErrorLog: func(r *http.Request, err error) { logged = err },
+	}
+
+	tt := []struct {
+		name   string
+		query  string
+		status int
+		body   string
+	}{
+		{
+			name:   "aliased",
+			query:  "filter[logic]=and&filter[filters][0][field]=name&filter[filters][0][operator]=eq&filter[filters][0][value]=Hari&sort[0][field]=age&sort[0][dir]=desc",
+			status: http.StatusOK,
+			body:   `{"data":[{"filter":"fullname = \"Hari\"","sort":"age descending"}],"total":1}`,
+		},
+		{
+			name:   "field not allowed",
+			query:  "sort[0][field]=commission_fee&sort[0][dir]=desc",
+			status: http.StatusBadRequest,
+			body:   `{"data":[],"total":0,"errors":["kendohelper: request: field \"commission_fee\" is not allowed"]}`,
+		},
+		{
+			name:   "malformed filter",
+			query:  "filter[logic]=xor&filter[filters][0][field]=name&filter[filters][0][operator]=eq",
+			status: http.StatusBadRequest,
+			body:   `{"data":[],"total":0,"errors":["kendohelper: filter: unsupported logic \"xor\""]}`,
+		},
+		{
+			name:   "data error is not sent",
+			query:  "take=99",
+			status: http.StatusInternalServerError,
+			body:   `{"data":[],"total":0,"errors":["Internal Server Error"]}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/grid?"+tc.query, nil))
+			if recorder.Code != tc.status {
+				t.Errorf("%v status should be %v, got %v", tc.name, tc.status, recorder.Code)
+			}
+			if body := strings.TrimSpace(recorder.Body.String()); body != tc.body {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.body, body)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json; charset=utf-8" {
+				t.Errorf("%v content type should be JSON, got %v", tc.name, contentType)
+			}
+		})
+	}
+	if logged == nil || logged.Error() != "connection refused" {
+		t.Errorf("data error should be logged, got %v", logged)
+	}
+}
+
+func TestHandlerMiddleware(t *testing.T) {
+	handler := &kendohelper.Handler{Fields: map[string]string{"name": "fullname"}}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, ok := kendohelper.RequestFromContext(r.Context())
+		if !ok {
+			t.Errorf("request should be in the context")
+		}
+		json.NewEncoder(w).Encode(request.Sort)
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.Middleware(next).ServeHTTP(recorder, httptest.NewRequest("GET", "/grid?sort[0][field]=name&sort[0][dir]=asc", nil))
+	if body := strings.TrimSpace(recorder.Body.String()); body != `[{"Field":"fullname","Dir":"asc"}]` {
+		t.Errorf("next should get the aliased request, got %v", body)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.Middleware(next).ServeHTTP(recorder, httptest.NewRequest("GET", "/grid?sort[0][field]=age&sort[0][dir]=asc", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("invalid request should get %v, got %v", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func newFormRequest(values url.Values) *http.Request {
+	request := httptest.NewRequest("POST", "/grid", strings.NewReader(values.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return request
+}
+
+func newJSONRequest(body string) *http.Request {
+	request := httptest.NewRequest("POST", "/grid", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return request
+}
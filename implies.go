@@ -0,0 +1,233 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxImplicationTerms limits the number of terms of the normal forms built by Implies,
+// filters needing more terms aren't proven to imply anything.
+const MaxImplicationTerms = 256
+
+// Implies reports whether every row matched by f is also matched by other, e.g. "age gte 30" implies "age gte 25",
+// so rows of f can be filtered in memory from the rows of other, or f can be verified to be within the scope of other.
+// The check is conservative: true is always right, false means it can't be proven. Fields may be Mongo arrays,
+// matched by "eq", "lt", "contains", etc. when any element matches and by "neq", "doesnotcontain", etc. when every element does,
+// so such positive filters never imply negative ones (e.g. tags ["a", "b"] matches both tags eq "a" and tags eq "b").
+// Both filters are compared in their canonical form (see Canonical), an empty filter matches any row.
+func (f *Filter) Implies(other Filter) bool {
+	a, b := f.Canonical(), other.Canonical()
+	if b.isEmpty() {
+		return true
+	}
+	if a.isEmpty() {
+		return false
+	}
+
+	// a implies b when each conjunction of a (DNF) implies each disjunction of b (CNF).
+	conjunctions, ok := normalForm(negationNormal(a), "or")
+	if !ok {
+		return false
+	}
+	disjunctions, ok := normalForm(negationNormal(b), "and")
+	if !ok {
+		return false
+	}
+	for _, conjunction := range conjunctions {
+		for _, disjunction := range disjunctions {
+			if !termImplies(conjunction, disjunction) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// negationNormal pushes the negations of f down to the operators, negated "lt", "lte", "gt" and "gte" are kept as "not" literals.
+func negationNormal(f Filter) Filter {
+	if len(f.Filters) == 0 {
+		return f
+	}
+	if f.Logic == "not" {
+		return negate(Filter{Filters: f.Filters, Logic: "and"})
+	}
+	filter := Filter{Filters: make([]Filter, len(f.Filters)), Logic: f.Logic}
+	for i := range f.Filters {
+		filter.Filters[i] = negationNormal(f.Filters[i])
+	}
+	return filter
+}
+
+func negate(f Filter) Filter {
+	if len(f.Filters) == 0 {
+		if operator, ok := negatedOperators[f.Operator]; ok {
+			f.Operator = operator
+			return f
+		}
+		return Filter{Filters: []Filter{f}, Logic: "not"}
+	}
+	if len(f.Filters) == 1 {
+		if f.Logic == "not" {
+			return negationNormal(f.Filters[0])
+		}
+		return negate(f.Filters[0])
+	}
+	switch f.Logic {
+	case "not":
+		return negationNormal(Filter{Filters: f.Filters, Logic: "and"})
+	case "and", "or":
+		logic := "or"
+		if f.Logic == "or" {
+			logic = "and"
+		}
+		filter := Filter{Filters: make([]Filter, len(f.Filters)), Logic: logic}
+		for i := range f.Filters {
+			filter.Filters[i] = negate(f.Filters[i])
+		}
+		return filter
+	}
+	return f
+}
+
+// normalForm returns the terms of f joined with outer: DNF ("or" of conjunctions) or CNF ("and" of disjunctions).
+func normalForm(f Filter, outer string) ([][]Filter, bool) {
+	if len(f.Filters) == 0 || f.Logic == "not" {
+		return [][]Filter{{f}}, true
+	}
+	terms := [][]Filter{}
+	if f.Logic == outer {
+		for i := range f.Filters {
+			childTerms, ok := normalForm(f.Filters[i], outer)
+			if !ok {
+				return nil, false
+			}
+			terms = append(terms, childTerms...)
+			if len(terms) > MaxImplicationTerms {
+				return nil, false
+			}
+		}
+		return terms, true
+	}
+
+	// distribute the inner logic over the outer one
+	terms = [][]Filter{{}}
+	for i := range f.Filters {
+		childTerms, ok := normalForm(f.Filters[i], outer)
+		if !ok || len(terms)*len(childTerms) > MaxImplicationTerms {
+			return nil, false
+		}
+		product := make([][]Filter, 0, len(terms)*len(childTerms))
+		for _, term := range terms {
+			for _, childTerm := range childTerms {
+				product = append(product, append(append([]Filter{}, term...), childTerm...))
+			}
+		}
+		terms = product
+	}
+	return terms, true
+}
+
+// termImplies reports whether the conjunction implies the disjunction.
+func termImplies(conjunction, disjunction []Filter) bool {
+	for i := range conjunction {
+		for j := range disjunction {
+			if literalImplies(&conjunction[i], &disjunction[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// negativeOperators match a Mongo array when every element matches, see Implies.
+var negativeOperators = map[string]bool{
+	"neq":              true,
+	"isnotnull":        true,
+	"isnotempty":       true,
+	"doesnotstartwith": true,
+	"doesnotendwith":   true,
+	"doesnotcontain":   true,
+}
+
+// literalImplies reports whether the literal a (a filter having an operator or a negated one) implies the literal b.
+func literalImplies(a, b *Filter) bool {
+	if a.canonicalKey() == b.canonicalKey() {
+		return true
+	}
+	if len(a.Filters) != 0 || len(b.Filters) != 0 || a.Field != b.Field {
+		return false
+	}
+	if negativeOperators[b.Operator] && !negativeOperators[a.Operator] {
+		// an array matching a may have another element not matching b
+		return false
+	}
+
+	switch a.Operator {
+	case "eq", "isnull":
+		// the field's value is known, evaluate b against it.
+		value := a.Value
+		if a.Operator == "isnull" {
+			value = nil
+		}
+		filter := Filter{Field: "value", Operator: b.Operator, Value: b.Value}
+		matched, ok := filter.matchLeaf(map[string]interface{}{"value": value})
+		return matched && ok
+	}
+
+	switch a.Operator {
+	case "lt", "lte", "gt", "gte":
+		return rangeImplies(a, b)
+	}
+	return substringImplies(a, b)
+}
+
+func rangeImplies(a, b *Filter) bool {
+	c, ok := compareValues(a.Value, b.Value)
+	if !ok {
+		return false
+	}
+	switch a.Operator + " " + b.Operator {
+	case "gte gte", "gt gte", "gt gt":
+		return c >= 0
+	case "gte gt":
+		return c > 0
+	case "lte lte", "lt lte", "lt lt":
+		return c <= 0
+	case "lte lt":
+		return c < 0
+	}
+	return false
+}
+
+// substringImplies compares values matched case-insensitively, values having regex's special characters are only compared as is.
+func substringImplies(a, b *Filter) bool {
+	x, ok := a.Value.(string)
+	if !ok || regexp.QuoteMeta(x) != x {
+		return false
+	}
+	y, ok := b.Value.(string)
+	if !ok || regexp.QuoteMeta(y) != y {
+		return false
+	}
+	x, y = strings.ToLower(x), strings.ToLower(y)
+
+	switch a.Operator + " " + b.Operator {
+	case "startswith startswith":
+		return strings.HasPrefix(x, y)
+	case "endswith endswith":
+		return strings.HasSuffix(x, y)
+	case "startswith contains", "endswith contains", "contains contains":
+		return strings.Contains(x, y)
+	case "doesnotcontain doesnotcontain", "doesnotcontain doesnotstartwith", "doesnotcontain doesnotendwith":
+		return strings.Contains(y, x)
+	case "doesnotstartwith doesnotstartwith":
+		return strings.HasPrefix(y, x)
+	case "doesnotendwith doesnotendwith":
+		return strings.HasSuffix(y, x)
+	}
+	return false
+}
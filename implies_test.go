@@ -0,0 +1,67 @@
+package kendohelper_test
+
+import (
+	"testing"
+
+	"github.com/muktihari/kendohelper"
+)
+
+func TestFilterImplies(t *testing.T) {
+	leaf := func(field, operator string, value interface{}) kendohelper.Filter {
+		return kendohelper.Filter{field, operator, value, nil, ""}
+	}
+	group := func(logic string, filters ...kendohelper.Filter) kendohelper.Filter {
+		return kendohelper.Filter{"", "", "", filters, logic}
+	}
+
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		other    kendohelper.Filter
+		expected bool
+	}{
+		{"anything implies empty", leaf("Age", "gte", 30), kendohelper.Filter{}, true},
+		{"empty doesn't imply", kendohelper.Filter{}, leaf("Age", "gte", 30), false},
+		{"same", leaf("Age", "gte", 30), leaf("Age", "gte", 30.0), true},
+		{"narrower gte", leaf("Age", "gte", 30), leaf("Age", "gte", 25), true},
+		{"wider gte", leaf("Age", "gte", 25), leaf("Age", "gte", 30), false},
+		{"gt implies gte of the same value", leaf("Age", "gt", 30), leaf("Age", "gte", 30), true},
+		{"gte doesn't imply gt of the same value", leaf("Age", "gte", 30), leaf("Age", "gt", 30), false},
+		{"narrower lt", leaf("Age", "lt", 20), leaf("Age", "lte", 25), true},
+		{"range doesn't imply neq of arrays", leaf("Age", "gte", 30), leaf("Age", "neq", 20), false},
+		{"range doesn't imply isnotnull of arrays", leaf("Age", "lt", 30), leaf("Age", "isnotnull", nil), false},
+		{"different types", leaf("Age", "gte", 30), leaf("Age", "gte", "25"), false},
+		{"different fields", leaf("Age", "gte", 30), leaf("Score", "gte", 25), false},
+		{"eq is evaluated", leaf("Age", "eq", 30), leaf("Age", "gte", 25), true},
+		{"eq outside", leaf("Age", "eq", 20), leaf("Age", "gte", 25), false},
+		{"eq implies contains", leaf("Name", "eq", "Hari"), leaf("Name", "contains", "ar"), true},
+		{"eq doesn't imply neq of arrays", leaf("Tags", "eq", "x"), leaf("Tags", "neq", "y"), false},
+		{"eq doesn't imply doesnotcontain of arrays", leaf("Tags", "eq", "x"), leaf("Tags", "doesnotcontain", "y"), false},
+		{"eq doesn't imply isnotempty of arrays", leaf("Tags", "eq", "x"), leaf("Tags", "isnotempty", ""), false},
+		{"narrower startswith", leaf("Name", "startswith", "Har"), leaf("Name", "startswith", "ha"), true},
+		{"startswith implies contains", leaf("Name", "startswith", "Har"), leaf("Name", "contains", "ar"), true},
+		{"regex isn't compared", leaf("Name", "startswith", "H.r"), leaf("Name", "startswith", "H"), false},
+		{"narrower doesnotcontain", leaf("Name", "doesnotcontain", "ar"), leaf("Name", "doesnotcontain", "har"), true},
+		{"and implies its filter", group("and", leaf("Name", "eq", "Hari"), leaf("Age", "gte", 30)), leaf("Age", "gte", 25), true},
+		{"filter doesn't imply and", leaf("Age", "gte", 30), group("and", leaf("Name", "eq", "Hari"), leaf("Age", "gte", 25)), false},
+		{"in implies wider in", group("or", leaf("City", "eq", "Jakarta"), leaf("City", "eq", "Bandung")),
+			group("or", leaf("City", "eq", "Bandung"), leaf("City", "eq", "Jakarta"), leaf("City", "eq", "Surabaya")), true},
+		{"in doesn't imply narrower in", group("or", leaf("City", "eq", "Jakarta"), leaf("City", "eq", "Bandung")), leaf("City", "eq", "Jakarta"), false},
+		{"distributed", group("and", leaf("Dept", "eq", "IT"), group("or", leaf("Age", "gte", 30), leaf("Age", "lt", 20))),
+			group("or", leaf("Age", "gte", 25), leaf("Age", "lte", 20)), true},
+		{"scope", group("and", leaf("Tenant", "eq", "A"), leaf("Name", "contains", "har")),
+			group("and", leaf("Tenant", "eq", "A")), true},
+		{"not pushed down", group("not", leaf("Name", "startswith", "ar")), leaf("Name", "doesnotstartwith", "ar"), true},
+		{"eq of different values isn't out of scope", group("and", leaf("Tags", "eq", "a"), leaf("Tags", "eq", "b")), leaf("Tenant", "eq", "A"), false},
+		{"eq of different values implies each", group("and", leaf("Tags", "eq", "a"), leaf("Tags", "eq", "b")), leaf("Tags", "eq", "b"), true},
+		{"ignored filter matches anything", leaf("Age", "foo", 30), leaf("Age", "gte", 25), false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.Implies(tc.other); got != tc.expected {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, got)
+			}
+		})
+	}
+}
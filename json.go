@@ -0,0 +1,208 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.telerik.com/kendo-ui/api/javascript/data/datasource/configuration/filter
+ * https://docs.telerik.com/kendo-ui/api/javascript/data/datasource/configuration/sort
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxFilterDepth is the maximum nesting level of filters accepted by Filter's UnmarshalJSON.
+var MaxFilterDepth = 16
+
+// MaxFilterNodes is the maximum number of filters (groups included) accepted by Filter's UnmarshalJSON.
+var MaxFilterNodes = 256
+
+// MaxSortElems is the maximum number of sort elements accepted by Sort's UnmarshalJSON.
+var MaxSortElems = 32
+
+// DecodeError is returned when a Kendo filter or sort payload is malformed.
+type DecodeError struct {
+	Path    string
+	Message string
+}
+
+func (e *DecodeError) Error() string {
+	return "kendohelper: " + e.Path + ": " + e.Message
+}
+
+// UnmarshalJSON decodes Kendo's filter object strictly.
+// Numbers in value are kept as json.Number so big IDs don't lose their precision,
+// unknown keys, malformed "filters" and payloads exceeding MaxFilterDepth or MaxFilterNodes are rejected.
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	nodes := 0
+	filter, err := decodeFilter(data, "filter", 1, &nodes)
+	if err != nil {
+		return err
+	}
+	*f = filter
+	return nil
+}
+
+func decodeFilter(data []byte, path string, depth int, nodes *int) (Filter, error) {
+	filter := Filter{}
+	if isJSONNull(data) {
+		return filter, nil
+	}
+	if depth > MaxFilterDepth {
+		return filter, &DecodeError{path, "exceeds maximum depth of " + strconv.Itoa(MaxFilterDepth)}
+	}
+	*nodes++
+	if *nodes > MaxFilterNodes {
+		return filter, &DecodeError{path, "exceeds maximum number of filters of " + strconv.Itoa(MaxFilterNodes)}
+	}
+
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return filter, &DecodeError{path, "must be an object"}
+	}
+
+	var children []json.RawMessage
+	for _, key := range sortedKeys(raw) {
+		value := raw[key]
+		var err error
+		switch strings.ToLower(key) {
+		case "field":
+			err = decodeString(value, &filter.Field)
+		case "operator":
+			err = decodeString(value, &filter.Operator)
+		case "logic":
+			err = decodeString(value, &filter.Logic)
+		case "value":
+			filter.Value, err = decodeValue(value)
+		case "filters":
+			if !isJSONNull(value) && json.Unmarshal(value, &children) != nil {
+				err = fmt.Errorf("must be an array")
+			}
+		case "ignorecase":
+			// ignoreCase is sent by kendo for string filters, string operators are case-insensitive anyway.
+			var ignoreCase bool
+			if json.Unmarshal(value, &ignoreCase) != nil {
+				err = fmt.Errorf("must be a boolean")
+			}
+		default:
+			return filter, &DecodeError{path, "unknown key " + strconv.Quote(key)}
+		}
+		if err != nil {
+			return filter, &DecodeError{path + "." + key, err.Error()}
+		}
+	}
+
+	if len(children) == 0 {
+		if filter.Operator != "" && filter.Field == "" {
+			return filter, &DecodeError{path, "operator " + strconv.Quote(filter.Operator) + " has no field"}
+		}
+		if filter.Field != "" && filter.Operator == "" {
+			return filter, &DecodeError{path, "field " + strconv.Quote(filter.Field) + " has no operator"}
+		}
+		return filter, nil
+	}
+
+	if filter.Field != "" || filter.Operator != "" || filter.Value != nil {
+		// converters ignore them on a group, the payload is ambiguous
+		return filter, &DecodeError{path, "has both filters and field, operator or value"}
+	}
+	if filter.Logic != "and" && filter.Logic != "or" && filter.Logic != "not" {
+		return filter, &DecodeError{path, "unsupported logic " + strconv.Quote(filter.Logic)}
+	}
+
+	filter.Filters = make([]Filter, len(children))
+	for i, child := range children {
+		var err error
+		filter.Filters[i], err = decodeFilter(child, path+".filters["+strconv.Itoa(i)+"]", depth+1, nodes)
+		if err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+// UnmarshalJSON decodes Kendo's sort array strictly.
+// Unknown keys, unsupported dir and arrays longer than MaxSortElems are rejected.
+func (s *Sort) UnmarshalJSON(data []byte) error {
+	if isJSONNull(data) {
+		*s = nil
+		return nil
+	}
+	elems := []json.RawMessage{}
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return &DecodeError{"sort", "must be an array"}
+	}
+	if len(elems) > MaxSortElems {
+		return &DecodeError{"sort", "exceeds maximum number of elements of " + strconv.Itoa(MaxSortElems)}
+	}
+
+	sort := make(Sort, len(elems))
+	for i, elem := range elems {
+		path := "sort[" + strconv.Itoa(i) + "]"
+		raw := map[string]json.RawMessage{}
+		if err := json.Unmarshal(elem, &raw); err != nil {
+			return &DecodeError{path, "must be an object"}
+		}
+		for _, key := range sortedKeys(raw) {
+			var err error
+			switch strings.ToLower(key) {
+			case "field":
+				err = decodeString(raw[key], &sort[i].Field)
+			case "dir":
+				err = decodeString(raw[key], &sort[i].Dir)
+			default:
+				return &DecodeError{path, "unknown key " + strconv.Quote(key)}
+			}
+			if err != nil {
+				return &DecodeError{path + "." + key, err.Error()}
+			}
+		}
+		if sort[i].Field == "" {
+			return &DecodeError{path, "has no field"}
+		}
+		if sort[i].Dir != "" && sort[i].Dir != "asc" && sort[i].Dir != "desc" {
+			return &DecodeError{path, "unsupported dir " + strconv.Quote(sort[i].Dir)}
+		}
+	}
+	*s = sort
+	return nil
+}
+
+func decodeString(data []byte, dest *string) error {
+	if isJSONNull(data) {
+		return nil
+	}
+	if json.Unmarshal(data, dest) != nil {
+		return fmt.Errorf("must be a string")
+	}
+	return nil
+}
+
+func decodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func isJSONNull(data []byte) bool {
+	return string(bytes.TrimSpace(data)) == "null"
+}
+
+func sortedKeys(raw map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,223 @@
+package kendohelper_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+func TestFilterUnmarshalJSON(t *testing.T) {
+	tt := []struct {
+		name     string
+		payload  string
+		expected kendohelper.Filter
+	}{
+		{
+			name:     "null",
+			payload:  `null`,
+			expected: kendohelper.Filter{},
+		},
+		{
+			name:     "empty group",
+			payload:  `{"logic":"and","filters":[]}`,
+			expected: kendohelper.Filter{"", "", nil, nil, "and"},
+		},
+		{
+			name:    "numbers are kept as json.Number",
+			payload: `{"logic":"and","filters":[{"field":"ID","operator":"eq","value":9007199254740993,"ignoreCase":true}]}`,
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"ID", "eq", json.Number("9007199254740993"), nil, ""},
+			}, "and"},
+		},
+		{
+			name:    "keys are case-insensitive",
+			payload: `{"Logic":"or","Filters":[{"Field":"Name","Operator":"contains","Value":"hari"}]}`,
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Name", "contains", "hari", nil, ""},
+			}, "or"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := kendohelper.Filter{}
+			if err := json.Unmarshal([]byte(tc.payload), &filter); err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(filter, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, filter)
+			}
+		})
+	}
+}
+
+func TestFilterUnmarshalJSONError(t *testing.T) {
+	tt := []struct {
+		name    string
+		payload string
+		err     string
+	}{
+		{
+			name:    "not an object",
+			payload: `[]`,
+			err:     "kendohelper: filter: must be an object",
+		},
+		{
+			name:    "unknown key",
+			payload: `{"logic":"and","filters":[{"field":"Name","operator":"eq","value":"x","$where":"1"}]}`,
+			err:     `kendohelper: filter.filters[0]: unknown key "$where"`,
+		},
+		{
+			name:    "filters is not an array",
+			payload: `{"logic":"and","filters":{"field":"Name"}}`,
+			err:     "kendohelper: filter.filters: must be an array",
+		},
+		{
+			name:    "operator and filters without logic",
+			payload: `{"field":"Name","operator":"eq","filters":[{"field":"Name","operator":"eq","value":"x"}]}`,
+			err:     "kendohelper: filter: has both filters and field, operator or value",
+		},
+		{
+			name:    "operator and filters with logic",
+			payload: `{"logic":"and","field":"x","operator":"eq","filters":[{"field":"Name","operator":"eq","value":"x"}]}`,
+			err:     "kendohelper: filter: has both filters and field, operator or value",
+		},
+		{
+			name:    "nested operator and filters with logic",
+			payload: `{"logic":"and","filters":[{"field":"created_at","operator":"eq","value":"2019-01-01T00:00:00Z","logic":"and","filters":[{"field":"created_at","operator":"gte","value":"2019-01-01T00:00:00Z"}]}]}`,
+			err:     "kendohelper: filter.filters[0]: has both filters and field, operator or value",
+		},
+		{
+			name:    "value and filters",
+			payload: `{"logic":"or","filters":[{"logic":"and","value":1,"filters":[{"field":"Name","operator":"eq","value":"x"}]}]}`,
+			err:     "kendohelper: filter.filters[0]: has both filters and field, operator or value",
+		},
+		{
+			name:    "unsupported logic",
+			payload: `{"logic":"xor","filters":[{"field":"Name","operator":"eq","value":"x"}]}`,
+			err:     `kendohelper: filter: unsupported logic "xor"`,
+		},
+		{
+			name:    "field is not a string",
+			payload: `{"field":1,"operator":"eq"}`,
+			err:     "kendohelper: filter.field: must be a string",
+		},
+		{
+			name:    "operator without field",
+			payload: `{"operator":"eq","value":1}`,
+			err:     `kendohelper: filter: operator "eq" has no field`,
+		},
+		{
+			name:    "field without operator",
+			payload: `{"field":"Name","value":1}`,
+			err:     `kendohelper: filter: field "Name" has no operator`,
+		},
+		{
+			name:    "too deep",
+			payload: strings.Repeat(`{"logic":"and","filters":[`, kendohelper.MaxFilterDepth+1) + strings.Repeat(`]}`, kendohelper.MaxFilterDepth+1),
+			err:     "kendohelper: filter" + strings.Repeat(".filters[0]", kendohelper.MaxFilterDepth) + ": exceeds maximum depth of 16",
+		},
+		{
+			name:    "too many filters",
+			payload: `{"logic":"or","filters":[` + strings.Repeat(`{"field":"a","operator":"eq","value":1},`, kendohelper.MaxFilterNodes) + `{}]}`,
+			err:     "kendohelper: filter.filters[255]: exceeds maximum number of filters of 256",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := kendohelper.Filter{}
+			err := json.Unmarshal([]byte(tc.payload), &filter)
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSortUnmarshalJSON(t *testing.T) {
+	tt := []struct {
+		name     string
+		payload  string
+		expected kendohelper.Sort
+		err      string
+	}{
+		{
+			name:     "null",
+			payload:  `null`,
+			expected: nil,
+		},
+		{
+			name:    "field and dir",
+			payload: `[{"field":"Name","dir":"asc"},{"Field":"Age","Dir":"desc"}]`,
+			expected: kendohelper.Sort{
+				kendohelper.SortElem{"Name", "asc"},
+				kendohelper.SortElem{"Age", "desc"},
+			},
+		},
+		{
+			name:    "not an array",
+			payload: `{"field":"Name","dir":"asc"}`,
+			err:     "kendohelper: sort: must be an array",
+		},
+		{
+			name:    "unknown key",
+			payload: `[{"field":"Name","dir":"asc","compare":1}]`,
+			err:     `kendohelper: sort[0]: unknown key "compare"`,
+		},
+		{
+			name:    "unsupported dir",
+			payload: `[{"field":"Name","dir":"up"}]`,
+			err:     `kendohelper: sort[0]: unsupported dir "up"`,
+		},
+		{
+			name:    "no field",
+			payload: `[{"dir":"asc"}]`,
+			err:     "kendohelper: sort[0]: has no field",
+		},
+		{
+			name:    "too many elements",
+			payload: `[` + strings.Repeat(`{"field":"a"},`, kendohelper.MaxSortElems) + `{"field":"a"}]`,
+			err:     "kendohelper: sort: exceeds maximum number of elements of 32",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sort := kendohelper.Sort{}
+			err := json.Unmarshal([]byte(tc.payload), &sort)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("%v should be %v, got %v", tc.name, tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(sort, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, sort)
+			}
+		})
+	}
+}
+
+func TestToAggregateFilterJSONNumber(t *testing.T) {
+	filter := kendohelper.Filter{}
+	payload := `{"logic":"and","filters":[{"field":"ID","operator":"eq","value":9007199254740993},{"field":"Price","operator":"gt","value":1.5}]}`
+	if err := json.Unmarshal([]byte(payload), &filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := toolkit.M{"$and": []toolkit.M{
+		toolkit.M{"ID": int64(9007199254740993)},
+		toolkit.M{"Price": toolkit.M{"$gt": 1.5}},
+	}}
+	aggrFilter := filter.ToAggregateFilter()
+	if !reflect.DeepEqual(aggrFilter, expected) {
+		t.Errorf("json.Number should be %v, got %v", expected, aggrFilter)
+	}
+}
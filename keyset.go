@@ -0,0 +1,261 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://use-the-index-luke.com/no-offset
+ */
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Keyset is seek (keyset) pagination derived from Sort: instead of skipping rows, the next page starts
+// right after the last row of the previous page. Use it for kendo's endless scrolling on large collections.
+type Keyset struct {
+	Sort Sort
+}
+
+// sqlOperators are the SQL comparison operators of ToSQL.
+var sqlOperators = map[string]string{
+	"lt": "<",
+	"gt": ">",
+}
+
+// NewKeyset creates Keyset from sort ended by tieBreaker, which must be a unique field (e.g. "_id")
+// so that no row is skipped or repeated across pages. Sort elements having no valid dir are left out.
+func NewKeyset(sort Sort, tieBreaker string) Keyset {
+	keysetSort := Sort{}
+	for _, v := range sort {
+		if v.Dir == "asc" || v.Dir == "desc" {
+			keysetSort = append(keysetSort, v)
+		}
+	}
+	return Keyset{Sort: keysetSort.WithTieBreaker(tieBreaker)}
+}
+
+// After returns Filter matching rows after last, the last row of the previous page, e.g. sorted by Name asc and _id asc:
+//
+//	(Name > last.Name) OR (Name = last.Name AND _id > last._id)
+//
+// Convert it with ToAggregateFilter or ToDBOXFilter and use the Sort (ToAggregateSort or ToDBOXSort) along with it.
+// Nested fields (e.g. "client.name") are looked up into nested maps of last.
+// Note: on mongo, "gt" and "lt" don't match null or missing fields, sort fields should always be present.
+func (k Keyset) After(last toolkit.M) (Filter, error) {
+	values, err := k.values(last)
+	if err != nil {
+		return Filter{}, err
+	}
+	filters := []Filter{}
+	for i, v := range k.Sort {
+		conditions := []Filter{}
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, Filter{Field: k.Sort[j].Field, Operator: "eq", Value: values[j]})
+		}
+		conditions = append(conditions, Filter{Field: v.Field, Operator: k.operator(v.Dir), Value: values[i]})
+		if len(conditions) == 1 {
+			filters = append(filters, conditions[0])
+			continue
+		}
+		filters = append(filters, And(conditions...))
+	}
+	return Or(filters...), nil
+}
+
+// ToSQL returns SQL's where clause matching rows after last along with its arguments, the placeholder is "?".
+// When all fields have the same dir, it's a row-value comparison: (Name, _id) > (?, ?),
+// otherwise it's expanded the way After does since row-value can only compare in one direction.
+// Fields must be plain (optionally dot-qualified) identifiers, they're not quoted.
+func (k Keyset) ToSQL(last toolkit.M) (string, []interface{}, error) {
+	for _, v := range k.Sort {
+		if !isSQLIdentifier(v.Field) {
+			return "", nil, fmt.Errorf("kendohelper: invalid SQL identifier %q", v.Field)
+		}
+	}
+	values, err := k.values(last)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(k.Sort) == 0 {
+		return "", nil, nil
+	}
+
+	sameDir := true
+	for _, v := range k.Sort {
+		sameDir = sameDir && v.Dir == k.Sort[0].Dir
+	}
+	if sameDir {
+		fields := make([]string, len(k.Sort))
+		placeholders := make([]string, len(k.Sort))
+		for i, v := range k.Sort {
+			fields[i] = v.Field
+			placeholders[i] = "?"
+		}
+		operator := sqlOperators[k.operator(k.Sort[0].Dir)]
+		if len(k.Sort) == 1 {
+			return fields[0] + " " + operator + " ?", values, nil
+		}
+		return "(" + strings.Join(fields, ", ") + ") " + operator + " (" + strings.Join(placeholders, ", ") + ")", values, nil
+	}
+
+	clauses := []string{}
+	args := []interface{}{}
+	for i, v := range k.Sort {
+		conditions := []string{}
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, k.Sort[j].Field+" = ?")
+			args = append(args, values[j])
+		}
+		conditions = append(conditions, v.Field+" "+sqlOperators[k.operator(v.Dir)]+" ?")
+		args = append(args, values[i])
+		clauses = append(clauses, "("+strings.Join(conditions, " AND ")+")")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args, nil
+}
+
+// EncodeCursor encodes the sort fields of last into an opaque URL-safe token to be sent to the client.
+// time.Time and bson.ObjectId keep their type when decoded.
+func (k Keyset) EncodeCursor(last toolkit.M) (string, error) {
+	values, err := k.values(last)
+	if err != nil {
+		return "", err
+	}
+	for i := range values {
+		switch v := values[i].(type) {
+		case time.Time:
+			values[i] = map[string]string{"$date": v.UTC().Format(time.RFC3339Nano)}
+		case bson.ObjectId:
+			values[i] = map[string]string{"$oid": v.Hex()}
+		}
+	}
+	data, err := json.Marshal(keysetCursor{Sort: k.signature(), Values: values})
+	if err != nil {
+		return "", fmt.Errorf("kendohelper: cannot encode cursor: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes the token made by EncodeCursor into the last row, ready to be used in After or ToSQL.
+// The token is rejected when it was made for a different Sort, e.g. after the user sorted the grid by another column.
+func (k Keyset) DecodeCursor(cursor string) (toolkit.M, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("kendohelper: malformed cursor")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	c := keysetCursor{}
+	if err := decoder.Decode(&c); err != nil {
+		return nil, errors.New("kendohelper: malformed cursor")
+	}
+	if c.Sort != k.signature() || len(c.Values) != len(k.Sort) {
+		return nil, errors.New("kendohelper: cursor doesn't match the sort")
+	}
+
+	last := toolkit.M{}
+	for i, v := range k.Sort {
+		value, err := decodeCursorValue(c.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		last[v.Field] = value
+	}
+	return last, nil
+}
+
+type keysetCursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+func (k Keyset) signature() string {
+	texts := make([]string, len(k.Sort))
+	for i, v := range k.Sort {
+		texts[i] = v.Field + " " + v.Dir
+	}
+	return strings.Join(texts, ",")
+}
+
+func (k Keyset) operator(dir string) string {
+	if dir == "desc" {
+		return "lt"
+	}
+	return "gt"
+}
+
+func (k Keyset) values(last toolkit.M) ([]interface{}, error) {
+	values := make([]interface{}, len(k.Sort))
+	for i, v := range k.Sort {
+		value, ok := lookupField(last, v.Field)
+		if !ok {
+			return nil, fmt.Errorf("kendohelper: last row has no %q field", v.Field)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func decodeCursorValue(value interface{}) (interface{}, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return normalizeValue(value), nil
+	}
+	if s, ok := m["$date"].(string); ok && len(m) == 1 {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, errors.New("kendohelper: malformed cursor")
+		}
+		return t.UTC(), nil
+	}
+	if s, ok := m["$oid"].(string); ok && len(m) == 1 && bson.IsObjectIdHex(s) {
+		return bson.ObjectIdHex(s), nil
+	}
+	return nil, errors.New("kendohelper: malformed cursor")
+}
+
+// lookupField gets field from row, a dotted field is looked up into nested maps unless row has it as is.
+func lookupField(row map[string]interface{}, field string) (interface{}, bool) {
+	if value, ok := row[field]; ok {
+		return value, true
+	}
+	dot := strings.Index(field, ".")
+	if dot < 0 {
+		return nil, false
+	}
+	var nested map[string]interface{}
+	switch v := row[field[:dot]].(type) {
+	case toolkit.M:
+		nested = v
+	case bson.M:
+		nested = v
+	case map[string]interface{}:
+		nested = v
+	default:
+		return nil, false
+	}
+	return lookupField(nested, field[dot+1:])
+}
+
+func isSQLIdentifier(field string) bool {
+	for _, part := range strings.Split(field, ".") {
+		if part == "" {
+			return false
+		}
+		for i, r := range part {
+			if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
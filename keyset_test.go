@@ -0,0 +1,190 @@
+package kendohelper_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestNewKeyset(t *testing.T) {
+	tt := []struct {
+		name     string
+		sort     kendohelper.Sort
+		expected kendohelper.Sort
+	}{
+		{
+			name:     "tie-breaker is appended",
+			sort:     kendohelper.Sort{kendohelper.SortElem{"Name", "desc"}, kendohelper.SortElem{"Age", ""}},
+			expected: kendohelper.Sort{kendohelper.SortElem{"Name", "desc"}, kendohelper.SortElem{"_id", "asc"}},
+		},
+		{
+			name:     "tie-breaker is kept",
+			sort:     kendohelper.Sort{kendohelper.SortElem{"_id", "desc"}},
+			expected: kendohelper.Sort{kendohelper.SortElem{"_id", "desc"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			keyset := kendohelper.NewKeyset(tc.sort, "_id")
+			if !reflect.DeepEqual(keyset.Sort, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, keyset.Sort)
+			}
+		})
+	}
+}
+
+func TestKeysetAfter(t *testing.T) {
+	last := toolkit.M{"Name": "Hari", "client": toolkit.M{"code": 7}, "_id": 10}
+	tt := []struct {
+		name     string
+		sort     kendohelper.Sort
+		expected toolkit.M
+	}{
+		{
+			name: "single field",
+			sort: kendohelper.Sort{},
+			expected: toolkit.M{"$or": []toolkit.M{
+				toolkit.M{"_id": toolkit.M{"$gt": 10}},
+			}},
+		},
+		{
+			name: "mixed dir and nested field",
+			sort: kendohelper.Sort{kendohelper.SortElem{"Name", "desc"}, kendohelper.SortElem{"client.code", "asc"}},
+			expected: toolkit.M{"$or": []toolkit.M{
+				toolkit.M{"Name": toolkit.M{"$lt": "Hari"}},
+				toolkit.M{"$and": []toolkit.M{
+					toolkit.M{"Name": "Hari"},
+					toolkit.M{"client.code": toolkit.M{"$gt": 7}},
+				}},
+				toolkit.M{"$and": []toolkit.M{
+					toolkit.M{"Name": "Hari"},
+					toolkit.M{"client.code": 7},
+					toolkit.M{"_id": toolkit.M{"$gt": 10}},
+				}},
+			}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := kendohelper.NewKeyset(tc.sort, "_id").After(last)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if got := filter.ToAggregateFilter(); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, got)
+			}
+		})
+	}
+
+	_, err := kendohelper.NewKeyset(kendohelper.Sort{kendohelper.SortElem{"Age", "asc"}}, "_id").After(last)
+	if err == nil || err.Error() != `kendohelper: last row has no "Age" field` {
+		t.Errorf("missing field should be rejected, got %v", err)
+	}
+}
+
+func TestKeysetToSQL(t *testing.T) {
+	last := toolkit.M{"name": "Hari", "age": 25, "id": 10}
+	tt := []struct {
+		name  string
+		sort  kendohelper.Sort
+		where string
+		args  []interface{}
+		err   string
+	}{
+		{
+			name:  "row-value",
+			sort:  kendohelper.Sort{kendohelper.SortElem{"name", "asc"}},
+			where: "(name, id) > (?, ?)",
+			args:  []interface{}{"Hari", 10},
+		},
+		{
+			name:  "descending single field",
+			sort:  kendohelper.Sort{kendohelper.SortElem{"id", "desc"}},
+			where: "id < ?",
+			args:  []interface{}{10},
+		},
+		{
+			name:  "mixed dir",
+			sort:  kendohelper.Sort{kendohelper.SortElem{"name", "asc"}, kendohelper.SortElem{"age", "desc"}},
+			where: "((name > ?) OR (name = ? AND age < ?) OR (name = ? AND age = ? AND id > ?))",
+			args:  []interface{}{"Hari", "Hari", 25, "Hari", 25, 10},
+		},
+		{
+			name: "invalid identifier",
+			sort: kendohelper.Sort{kendohelper.SortElem{"name; drop table users", "asc"}},
+			err:  `kendohelper: invalid SQL identifier "name; drop table users"`,
+		},
+	}
+
+	// SymbolOperators is for descriptions only, translating it must not change the SQL.
+	defer func(lt, gt string) {
+		kendohelper.SymbolOperators["lt"] = lt
+		kendohelper.SymbolOperators["gt"] = gt
+	}(kendohelper.SymbolOperators["lt"], kendohelper.SymbolOperators["gt"])
+	kendohelper.SymbolOperators["lt"] = "kurang dari"
+	kendohelper.SymbolOperators["gt"] = "lebih dari"
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			where, args, err := kendohelper.NewKeyset(tc.sort, "id").ToSQL(last)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("%v should be %v, got %v", tc.name, tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if where != tc.where || !reflect.DeepEqual(args, tc.args) {
+				t.Errorf("%v should be %v %v, got %v %v", tc.name, tc.where, tc.args, where, args)
+			}
+		})
+	}
+}
+
+func TestKeysetCursor(t *testing.T) {
+	keyset := kendohelper.NewKeyset(kendohelper.Sort{
+		kendohelper.SortElem{"created_at", "desc"},
+		kendohelper.SortElem{"client.code", "asc"},
+		kendohelper.SortElem{"amount", "asc"},
+	}, "_id")
+	last := toolkit.M{
+		"created_at": time.Date(2019, 01, 02, 03, 04, 05, 6, time.UTC),
+		"client":     bson.M{"code": "C-1"},
+		"amount":     12.5,
+		"_id":        bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c3"),
+	}
+
+	cursor, err := keyset.EncodeCursor(last)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := keyset.DecodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := toolkit.M{
+		"created_at":  time.Date(2019, 01, 02, 03, 04, 05, 6, time.UTC),
+		"client.code": "C-1",
+		"amount":      12.5,
+		"_id":         bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c3"),
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("decoded cursor should be %v, got %v", expected, decoded)
+	}
+
+	other := kendohelper.NewKeyset(kendohelper.Sort{kendohelper.SortElem{"amount", "asc"}}, "_id")
+	if _, err := other.DecodeCursor(cursor); err == nil || err.Error() != "kendohelper: cursor doesn't match the sort" {
+		t.Errorf("cursor of another sort should be rejected, got %v", err)
+	}
+	if _, err := keyset.DecodeCursor("not a cursor!"); err == nil || err.Error() != "kendohelper: malformed cursor" {
+		t.Errorf("malformed cursor should be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.telerik.com/kendo-ui/api/javascript/ui/grid/configuration/columns.values
+ */
+
+import (
+	"context"
+)
+
+// LookupValue is a key and its display text, like the values of kendo's foreign-key column.
+type LookupValue struct {
+	Value interface{} `json:"value"`
+	Text  string      `json:"text"`
+}
+
+// LookupFunc returns the keys whose display text matches operator and value, e.g. by querying the referenced collection.
+// Operator is never negated, e.g. "doesnotcontain" is looked up as "contains".
+type LookupFunc func(ctx context.Context, operator string, value interface{}) ([]interface{}, error)
+
+// Lookup translates the display field of a foreign-key column into its key field.
+// The keys are looked up in Values (enum), or else by Func.
+type Lookup struct {
+	KeyField string
+	Values   []LookupValue
+	Func     LookupFunc
+}
+
+// Lookups maps display fields to their Lookup, e.g.
+//
+//	kendohelper.Lookups{
+//		"status":       {KeyField: "status_id", Values: []kendohelper.LookupValue{{1, "Active"}, {2, "Inactive"}}},
+//		"categoryName": {KeyField: "category_id", Func: lookupCategories},
+//	}
+type Lookups map[string]Lookup
+
+// LookupError is returned by Translate when Func fails.
+type LookupError struct {
+	Field string
+	Err   error
+}
+
+func (e *LookupError) Error() string {
+	return "kendohelper: lookup of " + e.Field + ": " + e.Err.Error()
+}
+
+// Translate returns a brand new Filter whose filters on display fields are translated into "in" (filters having "eq" joined with "or")
+// over the matching keys, e.g. categoryName contains "bev" into category_id in (1, 5). Negated operators are translated into
+// the negation of the positive one, so rows having no key still match. When no key matches, the filter never matches.
+// "isnull" and "isnotnull" are translated into the key field as is.
+func (l Lookups) Translate(ctx context.Context, filter Filter) (Filter, error) {
+	filter = filter.DeepClone()
+	err := filter.WalkErr(func(filter Filter, info WalkInfo) (Filter, WalkAction, error) {
+		lookup, ok := l[filter.Field]
+		if !info.Leaf || !ok {
+			return filter, WalkContinue, nil
+		}
+		// filters ignored by the converters are kept as is
+		if _, ok := filter.canonical(); !ok {
+			return filter, WalkContinue, nil
+		}
+		translated, err := lookup.translate(ctx, filter)
+		if err != nil {
+			return filter, WalkStop, &LookupError{filter.Field, err}
+		}
+		return translated, WalkSkip, nil
+	}, nil)
+	if err != nil {
+		return Filter{}, err
+	}
+	return filter, nil
+}
+
+func (l *Lookup) translate(ctx context.Context, filter Filter) (Filter, error) {
+	switch filter.Operator {
+	case "isnull", "isnotnull":
+		filter.Field = l.KeyField
+		return filter, nil
+	}
+
+	operator, negated := filter.Operator, false
+	switch operator {
+	case "neq", "doesnotstartwith", "doesnotendwith", "doesnotcontain", "isnotempty":
+		operator, negated = negatedOperators[operator], true
+	}
+	keys, err := l.keys(ctx, operator, filter.Value)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	var translated Filter
+	if len(keys) == 0 {
+		// never matches
+		translated = And(Filter{Field: l.KeyField, Operator: "isnull"}, Filter{Field: l.KeyField, Operator: "isnotnull"})
+	} else if len(keys) == 1 {
+		translated = Filter{Field: l.KeyField, Operator: "eq", Value: keys[0]}
+	} else {
+		filters := make([]Filter, len(keys))
+		for i := range keys {
+			filters[i] = Filter{Field: l.KeyField, Operator: "eq", Value: keys[i]}
+		}
+		translated = Or(filters...)
+	}
+	if negated {
+		return Not(translated), nil
+	}
+	return translated, nil
+}
+
+func (l *Lookup) keys(ctx context.Context, operator string, value interface{}) ([]interface{}, error) {
+	if l.Values == nil && l.Func != nil {
+		return l.Func(ctx, operator, value)
+	}
+	keys := []interface{}{}
+	filter := Filter{Field: "text", Operator: operator, Value: value}
+	for _, v := range l.Values {
+		if matched, ok := filter.matchLeaf(map[string]interface{}{"text": v.Text}); matched && ok {
+			keys = append(keys, v.Value)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,121 @@
+package kendohelper_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+var errLookup = errors.New("connection refused")
+
+var testLookups = kendohelper.Lookups{
+	"status": {KeyField: "status_id", Values: []kendohelper.LookupValue{{1, "Active"}, {2, "Inactive"}, {3, "Archived"}}},
+	"category": {KeyField: "category_id", Func: func(ctx context.Context, operator string, value interface{}) ([]interface{}, error) {
+		if value == "fail" {
+			return nil, errLookup
+		}
+		return []interface{}{"c1"}, nil
+	}},
+}
+
+func TestLookupsTranslate(t *testing.T) {
+	never := kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+		kendohelper.Filter{Field: "status_id", Operator: "isnull"},
+		kendohelper.Filter{Field: "status_id", Operator: "isnotnull"},
+	}, "and"}
+
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected kendohelper.Filter
+	}{
+		{"contains into in", kendohelper.Filter{"status", "contains", "act", nil, ""}, kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{Field: "status_id", Operator: "eq", Value: 1},
+			kendohelper.Filter{Field: "status_id", Operator: "eq", Value: 2},
+		}, "or"}},
+		{"single key", kendohelper.Filter{"status", "eq", "Archived", nil, ""}, kendohelper.Filter{Field: "status_id", Operator: "eq", Value: 3}},
+		{"negated", kendohelper.Filter{"status", "doesnotstartwith", "a", nil, ""}, kendohelper.Filter{"", "", nil, []kendohelper.Filter{kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{Field: "status_id", Operator: "eq", Value: 1},
+			kendohelper.Filter{Field: "status_id", Operator: "eq", Value: 3},
+		}, "or"}}, "not"}},
+		{"no key matches", kendohelper.Filter{"status", "eq", "Deleted", nil, ""}, never},
+		{"isnull", kendohelper.Filter{"status", "isnull", nil, nil, ""}, kendohelper.Filter{"status_id", "isnull", nil, nil, ""}},
+		{"ignored filter is kept", kendohelper.Filter{"status", "contains", 1, nil, ""}, kendohelper.Filter{"status", "contains", 1, nil, ""}},
+		{"func", kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{"name", "eq", "Hari", nil, ""},
+			kendohelper.Filter{"category", "contains", "bev", nil, ""},
+		}, "and"}, kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{"name", "eq", "Hari", nil, ""},
+			kendohelper.Filter{Field: "category_id", Operator: "eq", Value: "c1"},
+		}, "and"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := testLookups.Translate(context.Background(), tc.filter)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(filter, tc.expected) {
+				t.Errorf("%v should be %#v, got %#v", tc.name, tc.expected, filter)
+			}
+		})
+	}
+
+	rows := []toolkit.M{toolkit.M{"status_id": 1}, toolkit.M{"status_id": 2}, toolkit.M{}}
+	filter, _ := testLookups.Translate(context.Background(), kendohelper.Filter{"status", "neq", "Active", nil, ""})
+	matched := 0
+	for _, row := range rows {
+		if filter.Match(row) {
+			matched++
+		}
+	}
+	if matched != 2 {
+		t.Errorf("neq should match the rows having another key or none, got %v", matched)
+	}
+
+	_, err := testLookups.Translate(context.Background(), kendohelper.Filter{"category", "eq", "fail", nil, ""})
+	if lookupErr, ok := err.(*kendohelper.LookupError); !ok || lookupErr.Err != errLookup {
+		t.Errorf("error should be LookupError, got %v", err)
+	}
+}
+
+func TestHandlerLookups(t *testing.T) {
+	handler := &kendohelper.Handler{
+		Lookups: testLookups,
+		Data: func(ctx context.Context, request kendohelper.Request) (kendohelper.Response, error) {
+			return kendohelper.Response{Data: []toolkit.M{toolkit.M{"filter": request.Filter.Expression()}}, Total: 1}, nil
+		},
+	}
+	query := "/grid?filter[logic]=and&filter[filters][0][operator]=eq&filter[filters][0][field]="
+
+	tt := []struct {
+		name   string
+		query  string
+		status int
+		body   string
+	}{
+		{"translated", query + "status&filter[filters][0][value]=Active", http.StatusOK, `{"data":[{"filter":"status_id = 1"}],"total":1}`},
+		{"lookup fails", query + "category&filter[filters][0][value]=fail", http.StatusInternalServerError, `{"data":[],"total":0,"errors":["Internal Server Error"]}`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", tc.query, nil))
+			if recorder.Code != tc.status {
+				t.Errorf("%v status should be %v, got %v", tc.name, tc.status, recorder.Code)
+			}
+			if body := strings.TrimSpace(recorder.Body.String()); body != tc.body {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.body, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.mongodb.com/manual/reference/bson-type-comparison-order/
+ */
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Match evaluates Filter against row in memory the way ToAggregateFilter's $match does, row is a map (e.g. toolkit.M)
+// or a struct whose fields are matched by their bson tag, json tag or name. Filters ignored by ToAggregateFilter
+// are ignored here too, so an empty Filter matches any row.
+// Note: unlike mongo, array fields aren't matched by their elements.
+func (f *Filter) Match(row interface{}) bool {
+	matched, ok := f.match(row)
+	return matched || !ok
+}
+
+// match returns ok false when the filter is ignored.
+func (f *Filter) match(row interface{}) (matched bool, ok bool) {
+	if len(f.Filters) == 0 {
+		return f.matchLeaf(row)
+	}
+
+	results := []bool{}
+	for i := range f.Filters {
+		if matched, ok := f.Filters[i].match(row); ok {
+			results = append(results, matched)
+		}
+	}
+	if len(results) == 0 {
+		return false, false
+	}
+	switch f.Logic {
+	case "and", "not":
+		all := true
+		for _, matched := range results {
+			all = all && matched
+		}
+		return all != (f.Logic == "not"), true
+	case "or":
+		for _, matched := range results {
+			if matched {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	return false, false
+}
+
+func (f *Filter) matchLeaf(row interface{}) (bool, bool) {
+	value := normalizeValue(f.Value)
+	valueStr, isString := value.(string)
+	if isString {
+		if t, ok := parseDefaultDate(valueStr); ok {
+			value = t
+		}
+	}
+	fieldValue, _ := FieldValue(row, f.Field)
+	fieldValue = textValue(fieldValue)
+
+	switch f.Operator {
+	case "isnull":
+		return fieldValue == nil, true
+	case "isnotnull":
+		return fieldValue != nil, true
+	case "eq":
+		return equalValues(fieldValue, value), true
+	case "neq":
+		return !equalValues(fieldValue, value), true
+	case "lt", "lte", "gt", "gte":
+		c, ok := compareValues(fieldValue, value)
+		if !ok {
+			return false, true
+		}
+		switch f.Operator {
+		case "lt":
+			return c < 0, true
+		case "lte":
+			return c <= 0, true
+		case "gt":
+			return c > 0, true
+		}
+		return c >= 0, true
+	}
+
+	if !isString {
+		return false, false
+	}
+	switch f.Operator {
+	case "startswith", "endswith", "contains":
+		return matchRegex(f.Operator, valueStr, fieldValue), true
+	case "doesnotstartwith", "doesnotendwith", "doesnotcontain":
+		regex := negatedRegex(f.Operator, valueStr)
+		return !matchRegex("", regex.Pattern, fieldValue), true
+	case "isempty":
+		return fieldValue == "", true
+	case "isnotempty":
+		return fieldValue != "", true
+	}
+	return false, false
+}
+
+// matchRegex matches fieldValue against the same regex used by ToAggregateFilter, an invalid regex matches nothing.
+func matchRegex(operator, value string, fieldValue interface{}) bool {
+	s, ok := fieldValue.(string)
+	if !ok {
+		return false
+	}
+	pattern := value
+	switch operator {
+	case "startswith":
+		pattern = `^` + value
+	case "endswith":
+		pattern = value + `$`
+	case "contains":
+		pattern = `.*` + value + `.*`
+	}
+	regex, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return false
+	}
+	return regex.MatchString(s)
+}
+
+// SortRows sorts rows (a slice of maps or structs) in memory the way ToAggregateSort's $sort does,
+// values of different types are ordered like mongo: null, numbers, strings, booleans then dates.
+func (s *Sort) SortRows(rows interface{}) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return
+	}
+	elems := *s
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := v.Index(i).Interface(), v.Index(j).Interface()
+		for _, elem := range elems {
+			if elem.Dir != "asc" && elem.Dir != "desc" {
+				continue
+			}
+			x, _ := FieldValue(a, elem.Field)
+			y, _ := FieldValue(b, elem.Field)
+			if c := orderValues(textValue(x), textValue(y)); c != 0 {
+				return (c < 0) == (elem.Dir == "asc")
+			}
+		}
+		return false
+	})
+}
+
+// FieldValue gets field of row, a map or a struct whose fields are matched by their bson tag, json tag or name
+// (case-insensitive). Dotted fields are looked up into nested maps and structs. Values are plain: numbers
+// of any kind become int64, uint64 or float64 and nil pointers become nil. Strings of named types (e.g. bson.ObjectId)
+// are kept as is.
+func FieldValue(row interface{}, field string) (interface{}, bool) {
+	v, ok := fieldValue(reflect.ValueOf(row), field)
+	if !ok {
+		return nil, false
+	}
+	return plainValue(v), true
+}
+
+func fieldValue(v reflect.Value, field string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+
+	name, rest := field, ""
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		if value := v.MapIndex(reflect.ValueOf(field).Convert(v.Type().Key())); value.IsValid() {
+			return value, true
+		}
+		if dot := strings.Index(field, "."); dot >= 0 {
+			name, rest = field[:dot], field[dot+1:]
+		}
+		value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !value.IsValid() || rest == "" {
+			return value, value.IsValid()
+		}
+		return fieldValue(value, rest)
+	case reflect.Struct:
+		if dot := strings.Index(field, "."); dot >= 0 {
+			name, rest = field[:dot], field[dot+1:]
+		}
+		value, ok := structField(v, name)
+		if !ok || rest == "" {
+			return value, ok
+		}
+		return fieldValue(value, rest)
+	}
+	return reflect.Value{}, false
+}
+
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	fallback := -1
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		for _, tag := range []string{sf.Tag.Get("bson"), sf.Tag.Get("json")} {
+			if tagName := strings.Split(tag, ",")[0]; tagName == name {
+				return v.Field(i), true
+			}
+		}
+		if sf.Name == name {
+			return v.Field(i), true
+		}
+		if fallback < 0 && strings.EqualFold(sf.Name, name) {
+			fallback = i
+		}
+	}
+	if fallback >= 0 {
+		return v.Field(fallback), true
+	}
+	return reflect.Value{}, false
+}
+
+func plainValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		if v.Type() == stringType {
+			return v.String()
+		}
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+var stringType = reflect.TypeOf("")
+
+// textValue converts strings of named types (e.g. type Status string) into string to be compared with the filter's value,
+// except bson.ObjectId which is binary.
+func textValue(value interface{}) interface{} {
+	if _, ok := value.(bson.ObjectId); ok {
+		return value
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+		return v.String()
+	}
+	return value
+}
+
+func equalValues(a, b interface{}) bool {
+	if c, ok := compareValues(a, plainValue(reflect.ValueOf(b))); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// orderValues orders values of any type, see SortRows.
+func orderValues(a, b interface{}) int {
+	if c, ok := compareValues(a, b); ok {
+		return c
+	}
+	if x, ok := a.(bson.ObjectId); ok {
+		if y, ok := b.(bson.ObjectId); ok {
+			return strings.Compare(string(x), string(y))
+		}
+	}
+	x, y := typeOrder(a), typeOrder(b)
+	return compareOrdered(x < y, x > y)
+}
+
+func typeOrder(value interface{}) int {
+	if value == nil {
+		return 0
+	}
+	if _, ok := toFloat64(value); ok {
+		return 1
+	}
+	switch value.(type) {
+	case string:
+		return 2
+	case bool:
+		return 4
+	case time.Time:
+		return 5
+	}
+	return 3
+}
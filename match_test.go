@@ -0,0 +1,165 @@
+package kendohelper_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type matchRole string
+
+type matchUser struct {
+	ID      bson.ObjectId `bson:"_id"`
+	Name    string        `json:"name"`
+	Role    matchRole
+	Age     int8
+	Score   *float64
+	Joined  time.Time `bson:"joined_at"`
+	Address struct {
+		City string `bson:"city"`
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	score := 7.5
+	user := matchUser{ID: bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c3"), Name: "Hikmatulloh Hari", Age: 25, Score: &score,
+		Role: "admin", Joined: time.Date(2019, 01, 02, 00, 00, 00, 00, time.UTC)}
+	user.Address.City = "Jakarta"
+	row := toolkit.M{"name": "Hikmatulloh Hari", "Age": 25, "Score": nil, "note": "", "address": bson.M{"city": "Jakarta"}}
+
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected bool
+	}{
+		{"eq json.Number on int8", kendohelper.Filter{"Age", "eq", json.Number("25"), nil, ""}, true},
+		{"eq ObjectId", kendohelper.Filter{"_id", "eq", bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c3"), nil, ""}, true},
+		{"neq ObjectId", kendohelper.Filter{"_id", "neq", bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c4"), nil, ""}, true},
+		{"eq named string", kendohelper.Filter{"Role", "eq", "admin", nil, ""}, true},
+		{"contains named string", kendohelper.Filter{"Role", "contains", "ADM", nil, ""}, true},
+		{"eq is case-sensitive", kendohelper.Filter{"name", "eq", "hikmatulloh hari", nil, ""}, false},
+		{"neq", kendohelper.Filter{"Name", "neq", "Hari", nil, ""}, true},
+		{"gt pointer", kendohelper.Filter{"Score", "gt", 7, nil, ""}, true},
+		{"lt time", kendohelper.Filter{"joined_at", "lt", "2019-01-03T00:00:00Z", nil, ""}, true},
+		{"gte of another type", kendohelper.Filter{"Age", "gte", "20", nil, ""}, false},
+		{"contains is case-insensitive", kendohelper.Filter{"name", "contains", "HARI", nil, ""}, true},
+		{"startswith", kendohelper.Filter{"name", "startswith", "hari", nil, ""}, false},
+		{"doesnotstartwith", kendohelper.Filter{"name", "doesnotstartwith", "hari", nil, ""}, true},
+		{"endswith nested", kendohelper.Filter{"Address.city", "endswith", "karta", nil, ""}, true},
+		{"doesnotcontain missing field", kendohelper.Filter{"missing", "doesnotcontain", "x", nil, ""}, true},
+		{"isnull missing field", kendohelper.Filter{"missing", "isnull", nil, nil, ""}, true},
+		{"unrecognized operator is ignored", kendohelper.Filter{"Age", "like", 1, nil, ""}, true},
+		{
+			name: "or with ignored filter",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Age", "lt", 20, nil, ""},
+				kendohelper.Filter{"Age", "", 25, nil, ""},
+			}, "or"},
+			expected: false,
+		},
+		{
+			name: "not",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Age", "gte", 20, nil, ""},
+				kendohelper.Filter{"Age", "lte", 30, nil, ""},
+			}, "not"},
+			expected: false,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if matched := tc.filter.Match(user); matched != tc.expected {
+				t.Errorf("%v should be %v on struct, got %v", tc.name, tc.expected, matched)
+			}
+		})
+	}
+
+	mapCases := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected bool
+	}{
+		{"eq", kendohelper.Filter{"Age", "eq", int64(25), nil, ""}, true},
+		{"dotted field", kendohelper.Filter{"address.city", "eq", "Jakarta", nil, ""}, true},
+		{"isnull", kendohelper.Filter{"Score", "isnull", nil, nil, ""}, true},
+		{"gt null", kendohelper.Filter{"Score", "gt", 1, nil, ""}, false},
+		{"isempty", kendohelper.Filter{"note", "isempty", "", nil, ""}, true},
+		{"isnotempty missing field", kendohelper.Filter{"missing", "isnotempty", "", nil, ""}, true},
+	}
+	for _, tc := range mapCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if matched := tc.filter.Match(row); matched != tc.expected {
+				t.Errorf("%v should be %v on map, got %v", tc.name, tc.expected, matched)
+			}
+		})
+	}
+}
+
+func TestSortSortRows(t *testing.T) {
+	rows := []toolkit.M{
+		toolkit.M{"Name": "Surya", "Age": 25},
+		toolkit.M{"Name": "Hari", "Age": 30},
+		toolkit.M{"Name": "Radit", "Age": nil},
+		toolkit.M{"Name": "Dewa", "Age": 25.0},
+		toolkit.M{"Name": "Anon", "Age": "unknown"},
+	}
+	sort := kendohelper.Sort{kendohelper.SortElem{"Age", "asc"}, kendohelper.SortElem{"Name", "desc"}}
+	sort.SortRows(rows)
+
+	names := []string{}
+	for _, row := range rows {
+		names = append(names, row["Name"].(string))
+	}
+	expected := []string{"Radit", "Surya", "Dewa", "Hari", "Anon"}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("rows should be sorted as %v, got %v", expected, names)
+		}
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	id := bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c3")
+	user := matchUser{ID: id, Role: "admin", Age: 25}
+
+	tt := []struct {
+		name     string
+		field    string
+		expected interface{}
+	}{
+		{"ObjectId is kept", "_id", id},
+		{"named string is kept", "Role", matchRole("admin")},
+		{"int8 becomes int64", "Age", int64(25)},
+		{"nil pointer", "Score", nil},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			value, ok := kendohelper.FieldValue(user, tc.field)
+			if !ok || value != tc.expected {
+				t.Errorf("%v should be %#v, got %#v", tc.name, tc.expected, value)
+			}
+		})
+	}
+}
+
+func TestSortSortRowsObjectId(t *testing.T) {
+	rows := []matchUser{
+		matchUser{ID: bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c3"), Name: "Hari"},
+		matchUser{ID: bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c5"), Name: "Surya"},
+		matchUser{ID: bson.ObjectIdHex("5c2c1b7e3f1e4a0001a1b2c4"), Name: "Radit"},
+	}
+	sort := kendohelper.Sort{kendohelper.SortElem{"_id", "desc"}}
+	sort.SortRows(rows)
+
+	expected := []string{"Surya", "Radit", "Hari"}
+	for i := range expected {
+		if rows[i].Name != expected[i] {
+			t.Fatalf("rows should be sorted as %v, got %v", expected, rows)
+		}
+	}
+}
@@ -0,0 +1,470 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.telerik.com/kendo-ui/framework/datasource/crud#odata
+ * https://www.odata.org/documentation/odata-version-2-0/uri-conventions/
+ * https://docs.oasis-open.org/odata/odata/v4.01/odata-v4.01-part2-url-conventions.html
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ODataVersion is the OData protocol version used by ToODataFilter.
+type ODataVersion int
+
+// Supported OData versions, kendo's DataSource uses ODataV2 for type "odata" and ODataV4 for type "odata-v4".
+const (
+	ODataV2 ODataVersion = 2
+	ODataV4 ODataVersion = 4
+)
+
+var odataOperators = map[string]string{
+	"eq":  "eq",
+	"neq": "ne",
+	"lt":  "lt",
+	"lte": "le",
+	"gt":  "gt",
+	"gte": "ge",
+}
+
+// ParseODataFilter parses OData v2 or v4 $filter into Filter,
+// e.g. substringof('x',Name) and Age ge 25.
+// Navigation paths (Client/Name) become dotted fields (Client.Name), tolower() and toupper() on fields are ignored
+// since string operators are case-insensitive.
+func ParseODataFilter(filter string) (Filter, error) {
+	p := &odataParser{parser{lexer: &odataLexer{input: filter}}}
+	if err := p.next(); err != nil {
+		return Filter{}, err
+	}
+	if p.token.kind == tokenEOF {
+		return Filter{}, nil
+	}
+	result, err := p.or()
+	if err != nil {
+		return Filter{}, err
+	}
+	if p.token.kind != tokenEOF {
+		return Filter{}, p.errorf("unexpected %s", p.token)
+	}
+	if result.Logic != "and" && result.Logic != "or" {
+		result = Filter{Filters: []Filter{result}, Logic: "and"}
+	}
+	return result, nil
+}
+
+// ParseODataOrderBy parses OData $orderby into Sort, e.g. Name desc,Age.
+func ParseODataOrderBy(orderBy string) (Sort, error) {
+	sort := Sort{}
+	for _, item := range strings.Split(orderBy, ",") {
+		parts := strings.Fields(item)
+		if len(parts) == 0 {
+			continue
+		}
+		sortElem := SortElem{Field: strings.Replace(parts[0], "/", ".", -1), Dir: "asc"}
+		if len(parts) > 2 || (len(parts) == 2 && parts[1] != "asc" && parts[1] != "desc") {
+			return nil, fmt.Errorf("kendohelper: invalid $orderby item %q", strings.TrimSpace(item))
+		}
+		if len(parts) == 2 {
+			sortElem.Dir = parts[1]
+		}
+		sort = append(sort, sortElem)
+	}
+	return sort, nil
+}
+
+// ToODataFilter converts Filter to OData $filter the same way kendo's DataSource does.
+// Filters ignored by the converters (unrecognized operator or logic) are left out.
+func (f *Filter) ToODataFilter(version ODataVersion) string {
+	if len(f.Filters) == 0 {
+		field := strings.Replace(f.Field, ".", "/", -1)
+		switch f.Operator {
+		case "isnull":
+			return field + " eq null"
+		case "isnotnull":
+			return field + " ne null"
+		case "isempty":
+			return field + " eq ''"
+		case "isnotempty":
+			return field + " ne ''"
+		case "eq", "neq", "lt", "lte", "gt", "gte":
+			return field + " " + odataOperators[f.Operator] + " " + odataValue(f.Value, version)
+		}
+
+		valueStr, ok := f.Value.(string)
+		if !ok {
+			return ""
+		}
+		value := odataValue(valueStr, version)
+		switch f.Operator {
+		case "contains":
+			if version == ODataV4 {
+				return "contains(" + field + "," + value + ")"
+			}
+			return "substringof(" + value + "," + field + ")"
+		case "doesnotcontain":
+			if version == ODataV4 {
+				return "indexof(" + field + "," + value + ") eq -1"
+			}
+			return "substringof(" + value + "," + field + ") eq false"
+		case "startswith", "endswith":
+			return f.Operator + "(" + field + "," + value + ")"
+		case "doesnotstartwith", "doesnotendwith":
+			return "not " + negatedOperators[f.Operator] + "(" + field + "," + value + ")"
+		}
+		return ""
+	}
+
+	if f.Logic != "and" && f.Logic != "or" && f.Logic != "not" {
+		return ""
+	}
+	filters := []string{}
+	for i := range f.Filters {
+		if filter := f.Filters[i].ToODataFilter(version); filter != "" {
+			filters = append(filters, filter)
+		}
+	}
+	if f.Logic == "not" && len(filters) != 0 {
+		filter := strings.Join(filters, " and ")
+		if len(filters) > 1 || !strings.HasPrefix(filter, "(") {
+			filter = "(" + filter + ")"
+		}
+		return "not " + filter
+	}
+	if len(filters) <= 1 {
+		return strings.Join(filters, "")
+	}
+	return "(" + strings.Join(filters, " "+f.Logic+" ") + ")"
+}
+
+// ToODataOrderBy converts Sort to OData $orderby, e.g. Name desc,Age.
+func (s *Sort) ToODataOrderBy() string {
+	orderBy := []string{}
+	for _, v := range *s {
+		if v.Dir != "asc" && v.Dir != "desc" {
+			continue
+		}
+		field := strings.Replace(v.Field, ".", "/", -1)
+		if v.Dir == "desc" {
+			field += " desc"
+		}
+		orderBy = append(orderBy, field)
+	}
+	return strings.Join(orderBy, ",")
+}
+
+func odataValue(value interface{}, version ODataVersion) string {
+	value = normalizeValue(value)
+	if s, ok := value.(string); ok {
+		if t, ok := parseDefaultDate(s); ok {
+			value = t
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.Replace(v, "'", "''", -1) + "'"
+	case time.Time:
+		if version == ODataV4 {
+			return v.UTC().Format("2006-01-02T15:04:05+00:00")
+		}
+		return "datetime'" + v.UTC().Format("2006-01-02T15:04:05") + "'"
+	}
+	return fmt.Sprint(value)
+}
+
+// odataLexer tokenizes OData $filter, numbers and v4 date-time literals are both tokenNumber.
+type odataLexer struct {
+	input  string
+	offset int
+}
+
+func (l *odataLexer) next() (token, error) {
+	for l.offset < len(l.input) && unicode.IsSpace(rune(l.input[l.offset])) {
+		l.offset++
+	}
+	start := l.offset
+	if start >= len(l.input) {
+		return token{kind: tokenEOF, offset: start}, nil
+	}
+
+	c := l.input[start]
+	switch {
+	case c == '\'':
+		text := ""
+		end := start + 1
+		for {
+			quote := strings.IndexByte(l.input[end:], '\'')
+			if quote < 0 {
+				return token{}, &ParseError{start, "unterminated string"}
+			}
+			text += l.input[end : end+quote]
+			end += quote + 1
+			if end >= len(l.input) || l.input[end] != '\'' {
+				break
+			}
+			text += "'"
+			end++
+		}
+		l.offset = end
+		return token{tokenString, text, start}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		end := start + 1
+		for end < len(l.input) && strings.IndexByte("0123456789.eE+-:TZ", l.input[end]) >= 0 {
+			end++
+		}
+		if end < len(l.input) && strings.IndexByte("mMdDfFlL", l.input[end]) >= 0 {
+			end++
+		}
+		l.offset = end
+		return token{tokenNumber, l.input[start:end], start}, nil
+	case strings.IndexByte("(),", c) >= 0:
+		l.offset++
+		return token{tokenSymbol, string(c), start}, nil
+	}
+
+	end := start
+	for _, r := range l.input[start:] {
+		if r != '/' && !isIdentifierRune(r, end == start) {
+			break
+		}
+		end += len(string(r))
+	}
+	if end == start {
+		return token{}, &ParseError{start, "unexpected character " + strconv.Quote(l.input[start:start+1])}
+	}
+	l.offset = end
+	return token{tokenIdent, l.input[start:end], start}, nil
+}
+
+type odataParser struct {
+	parser
+}
+
+func (p *odataParser) or() (Filter, error) {
+	return p.parseLogic("or", p.and)
+}
+
+func (p *odataParser) and() (Filter, error) {
+	return p.parseLogic("and", p.unary)
+}
+
+func (p *odataParser) unary() (Filter, error) {
+	if p.token.is("not") {
+		if err := p.next(); err != nil {
+			return Filter{}, err
+		}
+		filter, err := p.unary()
+		if err != nil {
+			return Filter{}, err
+		}
+		if _, ok := odataOperators[filter.Operator]; ok || len(filter.Filters) != 0 {
+			return Not(filter), nil
+		}
+		filter.Operator = negatedOperators[filter.Operator]
+		return filter, nil
+	}
+	if p.token.kind == tokenSymbol && p.token.text == "(" {
+		if err := p.next(); err != nil {
+			return Filter{}, err
+		}
+		filter, err := p.or()
+		if err != nil {
+			return Filter{}, err
+		}
+		return filter, p.expect(")")
+	}
+	return p.condition()
+}
+
+func (p *odataParser) condition() (Filter, error) {
+	if p.token.kind != tokenIdent {
+		return Filter{}, p.errorf("expected field or function, got %s", p.token)
+	}
+	name := strings.ToLower(p.token.text)
+	switch name {
+	case "substringof", "contains", "startswith", "endswith", "indexof":
+		return p.function(name)
+	}
+
+	field, err := p.field()
+	if err != nil {
+		return Filter{}, err
+	}
+	operator, err := p.operator()
+	if err != nil {
+		return Filter{}, err
+	}
+	value, err := p.literal()
+	if err != nil {
+		return Filter{}, err
+	}
+
+	filter := Filter{Field: field, Operator: operator, Value: value}
+	if value == nil && (operator == "eq" || operator == "neq") {
+		filter.Operator = map[string]string{"eq": "isnull", "neq": "isnotnull"}[operator]
+	}
+	if value == "" && (operator == "eq" || operator == "neq") {
+		filter.Operator = map[string]string{"eq": "isempty", "neq": "isnotempty"}[operator]
+	}
+	return filter, nil
+}
+
+// function parses string functions and their comparison with a boolean (or -1 for indexof).
+func (p *odataParser) function(name string) (Filter, error) {
+	offset := p.token.offset
+	if err := p.next(); err != nil {
+		return Filter{}, err
+	}
+	if err := p.expect("("); err != nil {
+		return Filter{}, err
+	}
+
+	var field string
+	var value interface{}
+	var err error
+	if name == "substringof" {
+		if value, err = p.literal(); err == nil {
+			if err = p.expect(","); err == nil {
+				field, err = p.field()
+			}
+		}
+	} else {
+		if field, err = p.field(); err == nil {
+			if err = p.expect(","); err == nil {
+				value, err = p.literal()
+			}
+		}
+	}
+	if err != nil {
+		return Filter{}, err
+	}
+	if _, ok := value.(string); !ok {
+		return Filter{}, &ParseError{offset, name + " expects a string"}
+	}
+	if err := p.expect(")"); err != nil {
+		return Filter{}, err
+	}
+
+	operator := name
+	if name == "substringof" || name == "indexof" {
+		operator = "contains"
+	}
+	filter := Filter{Field: field, Operator: operator, Value: value}
+	if name == "indexof" {
+		if !p.token.is("eq") && !p.token.is("ne") {
+			return Filter{}, p.errorf("expected eq -1 or ne -1, got %s", p.token)
+		}
+		if p.token.is("eq") {
+			filter.Operator = "doesnotcontain"
+		}
+		if err := p.next(); err != nil {
+			return Filter{}, err
+		}
+		if p.token.kind != tokenNumber || p.token.text != "-1" {
+			return Filter{}, p.errorf("expected -1, got %s", p.token)
+		}
+		return filter, p.next()
+	}
+
+	if !p.token.is("eq") && !p.token.is("ne") {
+		return filter, nil
+	}
+	negate := p.token.is("ne")
+	if err := p.next(); err != nil {
+		return Filter{}, err
+	}
+	value, err = p.literal()
+	if err != nil {
+		return Filter{}, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return Filter{}, &ParseError{offset, name + " can only be compared to a boolean"}
+	}
+	if b == negate {
+		filter.Operator = negatedOperators[filter.Operator]
+	}
+	return filter, nil
+}
+
+// field parses a property path, optionally wrapped in tolower() or toupper().
+func (p *odataParser) field() (string, error) {
+	if p.token.is("tolower") || p.token.is("toupper") {
+		if err := p.next(); err != nil {
+			return "", err
+		}
+		if err := p.expect("("); err != nil {
+			return "", err
+		}
+		field, err := p.field()
+		if err != nil {
+			return "", err
+		}
+		return field, p.expect(")")
+	}
+	if p.token.kind != tokenIdent || isKeyword(p.token.text) {
+		return "", p.errorf("expected field, got %s", p.token)
+	}
+	field := strings.Replace(p.token.text, "/", ".", -1)
+	return field, p.next()
+}
+
+func (p *odataParser) operator() (string, error) {
+	for operator, odataOperator := range odataOperators {
+		if p.token.is(odataOperator) {
+			return operator, p.next()
+		}
+	}
+	return "", p.errorf("expected operator, got %s", p.token)
+}
+
+func (p *odataParser) literal() (interface{}, error) {
+	token := p.token
+	switch {
+	case token.kind == tokenString:
+		return token.text, p.next()
+	case token.kind == tokenNumber:
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, token.text); err == nil {
+				return t.UTC(), p.next()
+			}
+		}
+		text := strings.TrimRight(token.text, "mMdDfFlL")
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return nil, p.errorf("invalid number %s", token.text)
+		}
+		return json.Number(text), p.next()
+	case token.is("true"), token.is("false"):
+		return token.is("true"), p.next()
+	case token.is("null"):
+		return nil, p.next()
+	case token.is("datetime"), token.is("datetimeoffset"), token.is("guid"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.token.kind != tokenString {
+			return nil, p.errorf("expected string, got %s", p.token)
+		}
+		if token.is("guid") {
+			guid := p.token.text
+			return guid, p.next()
+		}
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+			if t, err := time.Parse(layout, p.token.text); err == nil {
+				return t.UTC(), p.next()
+			}
+		}
+		return nil, p.errorf("invalid %s %s", token.text, p.token)
+	}
+	return nil, p.errorf("expected value, got %s", token)
+}
@@ -0,0 +1,227 @@
+package kendohelper_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+func TestParseODataFilter(t *testing.T) {
+	tt := []struct {
+		name     string
+		filter   string
+		expected kendohelper.Filter
+	}{
+		{
+			name:     "empty",
+			filter:   "",
+			expected: kendohelper.Filter{},
+		},
+		{
+			name:   "v2 substringof and comparison",
+			filter: "(substringof('x',Name) and Age ge 25)",
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Name", "contains", "x", nil, ""},
+				kendohelper.Filter{"Age", "gte", json.Number("25"), nil, ""},
+			}, "and"},
+		},
+		{
+			name:   "v4 functions, negation, tolower and navigation path",
+			filter: "contains(tolower(Client/Name),'o''neil') or indexof(Name,'x') eq -1 or not startswith(Name,'a') or endswith(Name,'z') eq false or substringof('y',Name) eq false",
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Client.Name", "contains", "o'neil", nil, ""},
+				kendohelper.Filter{"Name", "doesnotcontain", "x", nil, ""},
+				kendohelper.Filter{"Name", "doesnotstartwith", "a", nil, ""},
+				kendohelper.Filter{"Name", "doesnotendwith", "z", nil, ""},
+				kendohelper.Filter{"Name", "doesnotcontain", "y", nil, ""},
+			}, "or"},
+		},
+		{
+			name:   "null, empty, booleans, suffixed numbers and dates",
+			filter: "Name eq null and Note ne '' and Active eq true and Price lt 10.5M and CreatedAt gt datetime'2019-01-01T00:00:00' and UpdatedAt le 2019-01-02T00:00:00+00:00 and not (Age lt 5)",
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Name", "isnull", nil, nil, ""},
+				kendohelper.Filter{"Note", "isnotempty", "", nil, ""},
+				kendohelper.Filter{"Active", "eq", true, nil, ""},
+				kendohelper.Filter{"Price", "lt", json.Number("10.5"), nil, ""},
+				kendohelper.Filter{"CreatedAt", "gt", time.Date(2019, 01, 01, 00, 00, 00, 00, time.UTC), nil, ""},
+				kendohelper.Filter{"UpdatedAt", "lte", time.Date(2019, 01, 02, 00, 00, 00, 00, time.UTC), nil, ""},
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"Age", "lt", json.Number("5"), nil, ""},
+				}, "not"},
+			}, "and"},
+		},
+		{
+			name:   "not on group",
+			filter: "not (Age eq 1 or Age eq 2)",
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+					kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+						kendohelper.Filter{"Age", "eq", json.Number("1"), nil, ""},
+						kendohelper.Filter{"Age", "eq", json.Number("2"), nil, ""},
+					}, "or"},
+				}, "not"},
+			}, "and"},
+		},
+		{
+			name:   "single condition is wrapped in a group",
+			filter: "Id eq guid'0f8fad5b-d9cb-469f-a165-70867728950e'",
+			expected: kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+				kendohelper.Filter{"Id", "eq", "0f8fad5b-d9cb-469f-a165-70867728950e", nil, ""},
+			}, "and"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := kendohelper.ParseODataFilter(tc.filter)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(filter, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, filter)
+			}
+		})
+	}
+}
+
+func TestParseODataFilterEmptyString(t *testing.T) {
+	tt := []struct {
+		name     string
+		filter   string
+		expected toolkit.M
+	}{
+		{"eq", "Name eq ''", toolkit.M{"$and": []toolkit.M{toolkit.M{"Name": ""}}}},
+		{"ne", "Name ne ''", toolkit.M{"$and": []toolkit.M{toolkit.M{"Name": toolkit.M{"$ne": ""}}}}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := kendohelper.ParseODataFilter(tc.filter)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if match := filter.ToAggregateFilter(); !reflect.DeepEqual(match, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, match)
+			}
+		})
+	}
+}
+
+func TestParseODataFilterError(t *testing.T) {
+	tt := []struct {
+		name   string
+		filter string
+		err    string
+	}{
+		{
+			name:   "unknown operator",
+			filter: "Age like 1",
+			err:    `kendohelper: parse error at offset 4: expected operator, got "like"`,
+		},
+		{
+			name:   "function on non-string",
+			filter: "startswith(Name,1)",
+			err:    "kendohelper: parse error at offset 0: startswith expects a string",
+		},
+		{
+			name:   "indexof without -1",
+			filter: "indexof(Name,'x') eq 0",
+			err:    `kendohelper: parse error at offset 21: expected -1, got "0"`,
+		},
+		{
+			name:   "unterminated string",
+			filter: "Name eq 'x",
+			err:    "kendohelper: parse error at offset 8: unterminated string",
+		},
+		{
+			name:   "invalid datetime",
+			filter: "CreatedAt eq datetime'yesterday'",
+			err:    `kendohelper: parse error at offset 21: invalid datetime "yesterday"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := kendohelper.ParseODataFilter(tc.filter)
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.err, err)
+			}
+		})
+	}
+}
+
+func TestToODataFilter(t *testing.T) {
+	filter := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"Name", "contains", "o'neil", nil, ""},
+		kendohelper.Filter{"Name", "doesnotcontain", "x", nil, ""},
+		kendohelper.Filter{"Name", "doesnotstartwith", "a", nil, ""},
+		kendohelper.Filter{"Name", "isnull", nil, nil, ""},
+		kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"Age", "lt", 5, nil, ""},
+		}, "not"},
+		kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"Client.Age", "gte", 25, nil, ""},
+			kendohelper.Filter{"CreatedAt", "lt", "2019-01-02T00:00:00Z", nil, ""},
+			kendohelper.Filter{"Age", "startswith", 25, nil, ""},
+		}, "or"},
+	}, "and"}
+
+	tt := []struct {
+		name     string
+		version  kendohelper.ODataVersion
+		expected string
+	}{
+		{
+			name:     "v2",
+			version:  kendohelper.ODataV2,
+			expected: "(substringof('o''neil',Name) and substringof('x',Name) eq false and not startswith(Name,'a') and Name eq null and not (Age lt 5) and (Client/Age ge 25 or CreatedAt lt datetime'2019-01-02T00:00:00'))",
+		},
+		{
+			name:     "v4",
+			version:  kendohelper.ODataV4,
+			expected: "(contains(Name,'o''neil') and indexof(Name,'x') eq -1 and not startswith(Name,'a') and Name eq null and not (Age lt 5) and (Client/Age ge 25 or CreatedAt lt 2019-01-02T00:00:00+00:00))",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			odataFilter := filter.ToODataFilter(tc.version)
+			if odataFilter != tc.expected {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, odataFilter)
+			}
+			parsed, err := kendohelper.ParseODataFilter(odataFilter)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if parsed.ToODataFilter(tc.version) != odataFilter {
+				t.Errorf("%v should round-trip %v, got %v", tc.name, odataFilter, parsed.ToODataFilter(tc.version))
+			}
+		})
+	}
+}
+
+func TestODataOrderBy(t *testing.T) {
+	sort, err := kendohelper.ParseODataOrderBy("Name desc, Client/Age,CreatedAt asc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := kendohelper.Sort{
+		kendohelper.SortElem{"Name", "desc"},
+		kendohelper.SortElem{"Client.Age", "asc"},
+		kendohelper.SortElem{"CreatedAt", "asc"},
+	}
+	if !reflect.DeepEqual(sort, expected) {
+		t.Errorf("$orderby should be %v, got %v", expected, sort)
+	}
+	if orderBy := sort.ToODataOrderBy(); orderBy != "Name desc,Client/Age,CreatedAt" {
+		t.Errorf("$orderby should be %v, got %v", "Name desc,Client/Age,CreatedAt", orderBy)
+	}
+	if _, err := kendohelper.ParseODataOrderBy("Name down"); err == nil {
+		t.Errorf("invalid $orderby should return error")
+	}
+}
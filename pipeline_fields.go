@@ -0,0 +1,124 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.mongodb.com/manual/core/aggregation-pipeline-optimization/
+ */
+
+import (
+	"github.com/eaciit/toolkit"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// PipelineFields maps grid fields to their path at each stage of an aggregate pipeline built across collections.
+// Stage 0 is before the first $lookup, stage 1 is after it, and so on. An empty path means the field is not available yet, e.g.
+//
+//	kendohelper.PipelineFields{
+//		"name":        {"name", "name"},
+//		"client_name": {"", "clientdoc.name"},
+//	}
+//
+// Fields which are not mapped are kept as is and only available on the last stage.
+type PipelineFields map[string][]string
+
+// SplitFilter splits filter into $match filters per stage. Each filter is placed on the earliest stage
+// where all of its fields are available, so predicates on base collection's fields run before the $lookup.
+// Only "and" filters can be split, "or" and "not" filters are kept whole. Stages having no filter are nil.
+func (p PipelineFields) SplitFilter(filter Filter) []toolkit.M {
+	stages := make([][]Filter, p.stages())
+	for _, conjunct := range conjuncts(filter) {
+		stage := 0
+		conjunct = conjunct.DeepClone()
+		conjunct.Handle(func(filter Filter) Filter {
+			if s := p.stageOf(filter.Field); s > stage {
+				stage = s
+			}
+			return filter
+		})
+		conjunct.HandleField(func(field string) string {
+			return p.pathAt(field, stage)
+		})
+		stages[stage] = append(stages[stage], conjunct)
+	}
+
+	matches := make([]toolkit.M, len(stages))
+	for i := range stages {
+		if len(stages[i]) == 0 {
+			continue
+		}
+		filter := And(stages[i]...)
+		matches[i] = filter.ToAggregateFilter()
+	}
+	return matches
+}
+
+// SplitSort places $sort on the earliest stage where all of its fields are available, other stages are nil.
+// Stages after it must keep the order of documents (e.g. $lookup, $unwind, $match).
+func (p PipelineFields) SplitSort(sort Sort) []bson.D {
+	stage := 0
+	for _, v := range sort {
+		if v.Dir != "asc" && v.Dir != "desc" {
+			continue
+		}
+		if s := p.stageOf(v.Field); s > stage {
+			stage = s
+		}
+	}
+	sort = sort.DeepCopy()
+	sort.HandleField(func(field string) string {
+		return p.pathAt(field, stage)
+	})
+
+	sorts := make([]bson.D, p.stages())
+	if aggregateSort := sort.ToAggregateSort(); len(aggregateSort) != 0 {
+		sorts[stage] = aggregateSort
+	}
+	return sorts
+}
+
+func (p PipelineFields) stages() int {
+	stages := 1
+	for _, paths := range p {
+		if len(paths) > stages {
+			stages = len(paths)
+		}
+	}
+	return stages
+}
+
+func (p PipelineFields) stageOf(field string) int {
+	for i, path := range p[field] {
+		if path != "" {
+			return i
+		}
+	}
+	return p.stages() - 1
+}
+
+// pathAt returns the latest known path of field at stage.
+func (p PipelineFields) pathAt(field string, stage int) string {
+	paths := p[field]
+	for i := stage; i >= 0; i-- {
+		if i < len(paths) && paths[i] != "" {
+			return paths[i]
+		}
+	}
+	return field
+}
+
+// conjuncts flattens filter's "and" into filters which can be matched separately.
+func conjuncts(filter Filter) []Filter {
+	if filter.isEmpty() {
+		return nil
+	}
+	if len(filter.Filters) == 0 || filter.Logic != "and" {
+		return []Filter{filter}
+	}
+	filters := []Filter{}
+	for i := range filter.Filters {
+		filters = append(filters, conjuncts(filter.Filters[i])...)
+	}
+	return filters
+}
@@ -0,0 +1,140 @@
+package kendohelper_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var pipelineFields = kendohelper.PipelineFields{
+	"name":        {"name", "name"},
+	"client_id":   {"client_id", "clientdoc._id"},
+	"client_name": {"", "clientdoc.name"},
+}
+
+func TestPipelineFieldsSplitFilter(t *testing.T) {
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected []toolkit.M
+	}{
+		{
+			name: "base fields before lookup, joined fields after",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"name", "eq", "Hari", nil, ""},
+				kendohelper.Filter{"client_name", "eq", "ACME", nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"client_id", "eq", 1, nil, ""},
+					kendohelper.Filter{"client_id", "eq", 2, nil, ""},
+				}, "or"},
+			}, "and"},
+			expected: []toolkit.M{
+				toolkit.M{"$and": []toolkit.M{
+					toolkit.M{"name": "Hari"},
+					toolkit.M{"$or": []toolkit.M{
+						toolkit.M{"client_id": 1},
+						toolkit.M{"client_id": 2},
+					}},
+				}},
+				toolkit.M{"$and": []toolkit.M{
+					toolkit.M{"clientdoc.name": "ACME"},
+				}},
+			},
+		},
+		{
+			name: "or across stages is kept whole and renamed on the latest stage",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"client_id", "eq", 1, nil, ""},
+				kendohelper.Filter{"client_name", "eq", "ACME", nil, ""},
+			}, "or"},
+			expected: []toolkit.M{
+				nil,
+				toolkit.M{"$and": []toolkit.M{
+					toolkit.M{"$or": []toolkit.M{
+						toolkit.M{"clientdoc._id": 1},
+						toolkit.M{"clientdoc.name": "ACME"},
+					}},
+				}},
+			},
+		},
+		{
+			name: "unmapped field on the last stage",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"total", "gt", 10, nil, ""},
+			}, "and"},
+			expected: []toolkit.M{
+				nil,
+				toolkit.M{"$and": []toolkit.M{
+					toolkit.M{"total": toolkit.M{"$gt": 10}},
+				}},
+			},
+		},
+		{
+			name:     "empty",
+			filter:   kendohelper.Filter{},
+			expected: []toolkit.M{nil, nil},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			original := tc.filter.DeepClone()
+			matches := pipelineFields.SplitFilter(tc.filter)
+			if !reflect.DeepEqual(matches, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, matches)
+			}
+			if !reflect.DeepEqual(tc.filter, original) {
+				t.Errorf("%v should not change the filter, got %v", tc.name, tc.filter)
+			}
+		})
+	}
+}
+
+func TestPipelineFieldsSplitSort(t *testing.T) {
+	tt := []struct {
+		name     string
+		sort     kendohelper.Sort
+		expected []bson.D
+	}{
+		{
+			name: "base fields before lookup",
+			sort: kendohelper.Sort{
+				kendohelper.SortElem{"name", "asc"},
+				kendohelper.SortElem{"client_id", "desc"},
+				kendohelper.SortElem{"client_name", ""},
+			},
+			expected: []bson.D{
+				bson.D{{Name: "name", Value: 1}, {Name: "client_id", Value: -1}},
+				nil,
+			},
+		},
+		{
+			name: "joined field after lookup",
+			sort: kendohelper.Sort{
+				kendohelper.SortElem{"client_id", "asc"},
+				kendohelper.SortElem{"client_name", "desc"},
+			},
+			expected: []bson.D{
+				nil,
+				bson.D{{Name: "clientdoc._id", Value: 1}, {Name: "clientdoc.name", Value: -1}},
+			},
+		},
+		{
+			name:     "empty",
+			sort:     kendohelper.Sort{},
+			expected: []bson.D{nil, nil},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sorts := pipelineFields.SplitSort(tc.sort)
+			if !reflect.DeepEqual(sorts, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, sorts)
+			}
+		})
+	}
+}
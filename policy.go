@@ -0,0 +1,224 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PolicyRule names the rule of Policy which is violated.
+type PolicyRule string
+
+const (
+	RuleMaxDepth           PolicyRule = "maxDepth"
+	RuleMaxLeaves          PolicyRule = "maxLeaves"
+	RuleMaxInValues        PolicyRule = "maxInValues"
+	RuleMaxSortElems       PolicyRule = "maxSortElems"
+	RuleMaxGroupElems      PolicyRule = "maxGroupElems"
+	RuleMaxAggregateElems  PolicyRule = "maxAggregateElems"
+	RuleOperator           PolicyRule = "operator"
+	RuleMinSubstringLength PolicyRule = "minSubstringLength"
+)
+
+// substringOperators are operators scanning the field's value, which can't use an index.
+var substringOperators = map[string]bool{
+	"startswith":       true,
+	"doesnotstartwith": true,
+	"endswith":         true,
+	"doesnotendwith":   true,
+	"contains":         true,
+	"doesnotcontain":   true,
+}
+
+// Policy limits the cost of queries built from Filter, Sort, Group and Aggregate. Zero values mean unlimited.
+type Policy struct {
+	// MaxDepth is the maximum nesting level of filters, the root is on level 1.
+	MaxDepth int
+	// MaxLeaves is the maximum number of filters having an operator.
+	MaxLeaves int
+	// MaxInValues is the maximum number of values of an "in" ("eq" filters on the same field under an "or" filter, nested "or" filters included).
+	MaxInValues int
+	// MaxSortElems is the maximum number of sort elements.
+	MaxSortElems int
+	// MaxGroupElems is the maximum number of group elements (nested group levels).
+	MaxGroupElems int
+	// MaxAggregateElems is the maximum number of aggregate elements, of the request and of each group element.
+	MaxAggregateElems int
+	// Operators restricts the operators of fields, e.g. {"description": {"eq"}}. Fields not listed may use any operator.
+	Operators map[string][]string
+	// MinSubstringLength is the minimum length of values of "contains", "startswith", "endswith" and their negations.
+	MinSubstringLength int
+}
+
+// Violation is a violated rule of Policy at Path, e.g. filter.filters[0].
+type Violation struct {
+	Path    string
+	Rule    PolicyRule
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// PolicyError is returned by Check when some rules are violated.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	texts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		texts[i] = v.String()
+	}
+	return "kendohelper: " + strings.Join(texts, "; ")
+}
+
+// Check checks the filter, sort, group and aggregate of request, it returns *PolicyError having all violations or nil.
+func (p *Policy) Check(request Request) error {
+	violations := append(p.CheckFilter(request.Filter), p.CheckSort(request.Sort)...)
+	violations = append(violations, p.CheckGroup(request.Group)...)
+	violations = append(violations, p.CheckAggregate(request.Aggregate)...)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &PolicyError{Violations: violations}
+}
+
+// CheckFilter returns the violations of filter, filters ignored by the converters (unrecognized operator) aren't counted.
+func (p *Policy) CheckFilter(filter Filter) []Violation {
+	violations := []Violation{}
+	leaves := 0
+	deep := false
+	p.checkFilter(&filter, "filter", 1, false, &leaves, &deep, &violations)
+	if p.MaxLeaves > 0 && leaves > p.MaxLeaves {
+		violations = append(violations, Violation{"filter", RuleMaxLeaves,
+			"has " + strconv.Itoa(leaves) + " filters, exceeds maximum of " + strconv.Itoa(p.MaxLeaves)})
+	}
+	return violations
+}
+
+// checkFilter checks f at path, flattened is true when f's "eq" filters are counted by an "or" filter above it.
+func (p *Policy) checkFilter(f *Filter, path string, depth int, flattened bool, leaves *int, deep *bool, violations *[]Violation) {
+	if p.MaxDepth > 0 && depth > p.MaxDepth && !*deep {
+		*deep = true
+		*violations = append(*violations, Violation{path, RuleMaxDepth, "exceeds maximum depth of " + strconv.Itoa(p.MaxDepth)})
+	}
+
+	if len(f.Filters) == 0 {
+		if !filterOperators[f.Operator] {
+			return
+		}
+		*leaves++
+		if allowed, ok := p.Operators[f.Field]; ok && !containsString(allowed, f.Operator) {
+			*violations = append(*violations, Violation{path, RuleOperator,
+				"operator " + strconv.Quote(f.Operator) + " is not allowed on " + strconv.Quote(f.Field)})
+		}
+		if value, ok := f.Value.(string); ok && substringOperators[f.Operator] && utf8.RuneCountInString(value) < p.MinSubstringLength {
+			*violations = append(*violations, Violation{path, RuleMinSubstringLength,
+				"value of " + strconv.Quote(f.Operator) + " must have at least " + strconv.Itoa(p.MinSubstringLength) + " characters"})
+		}
+		return
+	}
+
+	if p.MaxInValues > 0 && !flattened {
+		if values := inValues(f); values > p.MaxInValues {
+			*violations = append(*violations, Violation{path, RuleMaxInValues,
+				"has " + strconv.Itoa(values) + " values, exceeds maximum of " + strconv.Itoa(p.MaxInValues)})
+		}
+	}
+	for i := range f.Filters {
+		childFlattened := (f.Logic == "or" || flattened) && flattensIntoOr(&f.Filters[i])
+		p.checkFilter(&f.Filters[i], path+".filters["+strconv.Itoa(i)+"]", depth+1, childFlattened, leaves, deep, violations)
+	}
+}
+
+// CheckSort returns the violations of sort, sort elements having no valid dir aren't counted.
+func (p *Policy) CheckSort(sort Sort) []Violation {
+	violations := []Violation{}
+	elems := len(sort.ToDBOXSort())
+	if p.MaxSortElems > 0 && elems > p.MaxSortElems {
+		violations = append(violations, Violation{"sort", RuleMaxSortElems,
+			"has " + strconv.Itoa(elems) + " elements, exceeds maximum of " + strconv.Itoa(p.MaxSortElems)})
+	}
+	return violations
+}
+
+// CheckGroup returns the violations of group, including the aggregates of its elements.
+func (p *Policy) CheckGroup(group []GroupElem) []Violation {
+	violations := []Violation{}
+	if p.MaxGroupElems > 0 && len(group) > p.MaxGroupElems {
+		violations = append(violations, Violation{"group", RuleMaxGroupElems,
+			"has " + strconv.Itoa(len(group)) + " elements, exceeds maximum of " + strconv.Itoa(p.MaxGroupElems)})
+	}
+	for i := range group {
+		violations = append(violations, p.checkAggregate("group["+strconv.Itoa(i)+"].aggregates", group[i].Aggregates)...)
+	}
+	return violations
+}
+
+// CheckAggregate returns the violations of aggregate, unrecognized aggregates aren't counted.
+func (p *Policy) CheckAggregate(aggregate []AggregateElem) []Violation {
+	return p.checkAggregate("aggregate", aggregate)
+}
+
+func (p *Policy) checkAggregate(path string, aggregate []AggregateElem) []Violation {
+	violations := []Violation{}
+	elems := 0
+	for _, v := range aggregate {
+		if _, ok := mongoAccumulators[v.Aggregate]; ok {
+			elems++
+		}
+	}
+	if p.MaxAggregateElems > 0 && elems > p.MaxAggregateElems {
+		violations = append(violations, Violation{path, RuleMaxAggregateElems,
+			"has " + strconv.Itoa(elems) + " elements, exceeds maximum of " + strconv.Itoa(p.MaxAggregateElems)})
+	}
+	return violations
+}
+
+// inValues counts the "eq" filters per field under an "or" filter, the way Expression formats "in",
+// and returns the highest count. Nested "or" filters are flattened (see flattensIntoOr) so nesting doesn't hide values,
+// other filters under the "or" don't hide them either.
+func inValues(f *Filter) int {
+	if f.Logic != "or" {
+		return 0
+	}
+	values := 0
+	countInValues(f, map[string]int{}, &values)
+	return values
+}
+
+func countInValues(f *Filter, counts map[string]int, values *int) {
+	for i := range f.Filters {
+		filter := &f.Filters[i]
+		if flattensIntoOr(filter) {
+			countInValues(filter, counts, values)
+			continue
+		}
+		if len(filter.Filters) != 0 || filter.Operator != "eq" {
+			continue
+		}
+		if counts[filter.Field]++; counts[filter.Field] > *values {
+			*values = counts[filter.Field]
+		}
+	}
+}
+
+// flattensIntoOr checks whether f is merged into the "or" filter above it by Canonical: an "or" or a group having a single filter.
+func flattensIntoOr(f *Filter) bool {
+	return len(f.Filters) != 0 && (f.Logic == "or" || len(f.Filters) == 1 && f.Logic == "and")
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package kendohelper_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muktihari/kendohelper"
+)
+
+func TestPolicyCheckFilter(t *testing.T) {
+	policy := &kendohelper.Policy{
+		MaxDepth:           3,
+		MaxLeaves:          4,
+		MaxInValues:        2,
+		Operators:          map[string][]string{"description": {"eq", "neq"}},
+		MinSubstringLength: 3,
+	}
+
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected []kendohelper.Violation
+	}{
+		{
+			name: "valid",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"description", "eq", "x", nil, ""},
+				kendohelper.Filter{"name", "contains", "har", nil, ""},
+				kendohelper.Filter{"name", "like", "", nil, ""},
+			}, "and"},
+			expected: []kendohelper.Violation{},
+		},
+		{
+			name: "operator and substring length",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"description", "contains", "hari", nil, ""},
+				kendohelper.Filter{"name", "doesnotstartwith", "ha", nil, ""},
+			}, "and"},
+			expected: []kendohelper.Violation{
+				kendohelper.Violation{"filter.filters[0]", kendohelper.RuleOperator, `operator "contains" is not allowed on "description"`},
+				kendohelper.Violation{"filter.filters[1]", kendohelper.RuleMinSubstringLength, `value of "doesnotstartwith" must have at least 3 characters`},
+			},
+		},
+		{
+			name: "in values, depth and leaves",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"status", "eq", "open", nil, ""},
+					kendohelper.Filter{"status", "eq", "closed", nil, ""},
+					kendohelper.Filter{"status", "eq", "draft", nil, ""},
+				}, "or"},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"", "", "", []kendohelper.Filter{
+						kendohelper.Filter{"a", "eq", 1, nil, ""},
+						kendohelper.Filter{"b", "eq", 1, nil, ""},
+					}, "and"},
+				}, "or"},
+			}, "and"},
+			expected: []kendohelper.Violation{
+				kendohelper.Violation{"filter.filters[0]", kendohelper.RuleMaxInValues, "has 3 values, exceeds maximum of 2"},
+				kendohelper.Violation{"filter.filters[1].filters[0].filters[0]", kendohelper.RuleMaxDepth, "exceeds maximum depth of 3"},
+				kendohelper.Violation{"filter", kendohelper.RuleMaxLeaves, "has 5 filters, exceeds maximum of 4"},
+			},
+		},
+		{
+			name: "in values mixed with other filters",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"status", "eq", "open", nil, ""},
+				kendohelper.Filter{"status", "neq", "x", nil, ""},
+				kendohelper.Filter{"owner", "eq", "hari", nil, ""},
+				kendohelper.Filter{"status", "eq", "closed", nil, ""},
+				kendohelper.Filter{"status", "eq", "draft", nil, ""},
+			}, "or"},
+			expected: []kendohelper.Violation{
+				kendohelper.Violation{"filter", kendohelper.RuleMaxInValues, "has 3 values, exceeds maximum of 2"},
+				kendohelper.Violation{"filter", kendohelper.RuleMaxLeaves, "has 5 filters, exceeds maximum of 4"},
+			},
+		},
+		{
+			name: "nested in values",
+			filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"c", "eq", "a", nil, ""},
+					kendohelper.Filter{"c", "eq", "b", nil, ""},
+				}, "or"},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"c", "eq", "c", nil, ""},
+				}, "and"},
+				kendohelper.Filter{"c", "eq", "d", nil, ""},
+			}, "or"},
+			expected: []kendohelper.Violation{
+				kendohelper.Violation{"filter", kendohelper.RuleMaxInValues, "has 4 values, exceeds maximum of 2"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := policy.CheckFilter(tc.filter)
+			if !reflect.DeepEqual(violations, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, violations)
+			}
+		})
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := &kendohelper.Policy{MaxSortElems: 1, MinSubstringLength: 2}
+	request := kendohelper.Request{
+		Filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"name", "contains", "h", nil, ""}}, "and"},
+		Sort:   kendohelper.Sort{kendohelper.SortElem{"name", "asc"}, kendohelper.SortElem{"age", "desc"}, kendohelper.SortElem{"id", ""}},
+	}
+	err := policy.Check(request)
+	expected := `kendohelper: filter.filters[0]: value of "contains" must have at least 2 characters; sort: has 2 elements, exceeds maximum of 1`
+	if err == nil || err.Error() != expected {
+		t.Errorf("error should be %v, got %v", expected, err)
+	}
+	if err := policy.Check(kendohelper.Request{}); err != nil {
+		t.Errorf("empty request should be valid, got %v", err)
+	}
+}
+
+func TestPolicyCheckGroupAggregate(t *testing.T) {
+	policy := &kendohelper.Policy{MaxGroupElems: 1, MaxAggregateElems: 2}
+	request := kendohelper.Request{
+		Group: []kendohelper.GroupElem{
+			kendohelper.GroupElem{"City", "asc", []kendohelper.AggregateElem{
+				kendohelper.AggregateElem{"Age", "sum"},
+				kendohelper.AggregateElem{"Age", "min"},
+				kendohelper.AggregateElem{"Age", "max"},
+			}},
+			kendohelper.GroupElem{"Role", "asc", nil},
+		},
+		Aggregate: []kendohelper.AggregateElem{
+			kendohelper.AggregateElem{"Age", "sum"},
+			kendohelper.AggregateElem{"Age", "median"},
+			kendohelper.AggregateElem{"Age", "count"},
+		},
+	}
+	err := policy.Check(request)
+	expected := `kendohelper: group: has 2 elements, exceeds maximum of 1; group[0].aggregates: has 3 elements, exceeds maximum of 2`
+	if err == nil || err.Error() != expected {
+		t.Errorf("error should be %v, got %v", expected, err)
+	}
+
+	request.Aggregate = append(request.Aggregate, kendohelper.AggregateElem{"Age", "average"})
+	violations := policy.CheckAggregate(request.Aggregate)
+	expectedViolations := []kendohelper.Violation{kendohelper.Violation{"aggregate", kendohelper.RuleMaxAggregateElems, "has 3 elements, exceeds maximum of 2"}}
+	if !reflect.DeepEqual(violations, expectedViolations) {
+		t.Errorf("violations should be %v, got %v", expectedViolations, violations)
+	}
+}
+
+func TestHandlerPolicy(t *testing.T) {
+	handler := &kendohelper.Handler{
+		Policy: &kendohelper.Policy{MaxSortElems: 1, Operators: map[string][]string{"name": {"eq"}}},
+	}
+	recorder := httptest.NewRecorder()
+	query := "sort[0][field]=name&sort[0][dir]=asc&sort[1][field]=age&sort[1][dir]=asc&filter[logic]=and&filter[filters][0][field]=name&filter[filters][0][operator]=contains&filter[filters][0][value]=hari"
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/grid?"+query, nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status should be %v, got %v", http.StatusBadRequest, recorder.Code)
+	}
+	expected := `{"data":[],"total":0,"errors":["kendohelper: filter.filters[0]: operator \"contains\" is not allowed on \"name\"","kendohelper: sort: has 2 elements, exceeds maximum of 1"]}`
+	if body := strings.TrimSpace(recorder.Body.String()); body != expected {
+		t.Errorf("body should be %v, got %v", expected, body)
+	}
+}
+
+func TestHandlerPolicyFinalRequest(t *testing.T) {
+	handler := &kendohelper.Handler{
+		Fields:  map[string]string{"status": "status", "name": "fullname"},
+		Lookups: testLookups,
+		Policy:  &kendohelper.Policy{MaxInValues: 1, Operators: map[string][]string{"fullname": {"eq"}}},
+	}
+	recorder := httptest.NewRecorder()
+	query := "filter[logic]=and&filter[filters][0][field]=status&filter[filters][0][operator]=contains&filter[filters][0][value]=act" +
+		"&filter[filters][1][field]=name&filter[filters][1][operator]=contains&filter[filters][1][value]=hari"
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/grid?"+query, nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status should be %v, got %v", http.StatusBadRequest, recorder.Code)
+	}
+	expected := `{"data":[],"total":0,"errors":["kendohelper: filter.filters[0]: has 2 values, exceeds maximum of 1","kendohelper: filter.filters[1]: operator \"contains\" is not allowed on \"fullname\""]}`
+	if body := strings.TrimSpace(recorder.Body.String()); body != expected {
+		t.Errorf("body should be %v, got %v", expected, body)
+	}
+}
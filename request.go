@@ -0,0 +1,141 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.telerik.com/kendo-ui/api/javascript/data/datasource/configuration/serverpaging
+ * https://docs.mongodb.com/manual/reference/operator/aggregation/facet/
+ */
+
+import (
+	"github.com/eaciit/toolkit"
+)
+
+// Request is kendo DataSource's request with serverPaging, serverSorting, serverFiltering, serverGrouping
+// and serverAggregates enabled.
+type Request struct {
+	Take      int
+	Skip      int
+	Page      int
+	PageSize  int
+	Sort      Sort
+	Filter    Filter
+	Group     []GroupElem
+	Aggregate []AggregateElem
+}
+
+// FacetResult is the single document returned by ToAggregatePipeline, use NewResponse to build the response.
+type FacetResult struct {
+	Data       []toolkit.M `bson:"data" json:"data"`
+	Total      int         `bson:"total" json:"total"`
+	Aggregates toolkit.M   `bson:"aggregates" json:"aggregates"`
+	// Groups has the aggregates of every group per group level, computed from all the filtered rows.
+	Groups [][]toolkit.M `bson:"groups" json:"groups"`
+}
+
+// Offset returns the number of rows to skip, from Skip or else from Page and PageSize.
+func (r *Request) Offset() int {
+	if r.Skip > 0 {
+		return r.Skip
+	}
+	if r.Take == 0 && r.Page > 1 && r.PageSize > 0 {
+		return (r.Page - 1) * r.PageSize
+	}
+	return 0
+}
+
+// Limit returns the number of rows of a page, from Take or else from PageSize. Zero means no limit.
+func (r *Request) Limit() int {
+	if r.Take > 0 {
+		return r.Take
+	}
+	if r.PageSize > 0 {
+		return r.PageSize
+	}
+	return 0
+}
+
+// ToAggregatePipeline builds the whole Mongo Pipeline returning both the page of data and the total count in one round trip:
+//
+//	[...stages, $match, $sort, $facet: {data: [$skip, $limit], total: [$count], aggregates: [$group], g0: [$group], ...}, $project]
+//
+// stages (e.g. $lookup, $addFields) run first so the grid can filter and sort their fields.
+// $match and $sort are left out when there is nothing to match or sort, the result is decoded into FacetResult.
+// The rows are sorted by the group fields first, so they can be grouped by NewResponse.
+// Aggregates of the groups are computed by a $group per group level, so they cover the whole groups rather than the page.
+func (r *Request) ToAggregatePipeline(stages ...toolkit.M) []toolkit.M {
+	pipe := r.matchPipeline(stages)
+	sort := r.sort()
+	if aggregateSort := sort.ToAggregateSort(); len(aggregateSort) != 0 {
+		pipe = append(pipe, toolkit.M{"$sort": aggregateSort})
+	}
+
+	// $facet doesn't accept an empty sub-pipeline.
+	data := []toolkit.M{toolkit.M{"$skip": r.Offset()}}
+	if limit := r.Limit(); limit > 0 {
+		data = append(data, toolkit.M{"$limit": limit})
+	}
+	facet := toolkit.M{
+		"data":  data,
+		"total": []toolkit.M{toolkit.M{"$count": "total"}},
+	}
+	project := toolkit.M{
+		"data":  1,
+		"total": toolkit.M{"$ifNull": []interface{}{toolkit.M{"$arrayElemAt": []interface{}{"$total.total", 0}}, 0}},
+	}
+	if len(r.Aggregate) != 0 {
+		facet["aggregates"] = []toolkit.M{aggregatesStage(nil, r.Aggregate)}
+		project["aggregates"] = toolkit.M{"$arrayElemAt": []interface{}{"$aggregates", 0}}
+	}
+	if r.hasGroupAggregates() {
+		groups := []interface{}{}
+		for i, v := range r.Group {
+			id := toolkit.M{}
+			for j := 0; j <= i; j++ {
+				id[groupKey(j)] = "$" + r.Group[j].Field
+			}
+			facet[groupKey(i)] = []toolkit.M{aggregatesStage(id, v.aggregates(r.Aggregate))}
+			groups = append(groups, "$"+groupKey(i))
+		}
+		project["groups"] = groups
+	}
+	return append(pipe, toolkit.M{"$facet": facet}, toolkit.M{"$project": project})
+}
+
+// ToCountPipeline builds Mongo Pipeline counting the filtered rows into a single document: {total: n}.
+// No document is returned when nothing matches.
+func (r *Request) ToCountPipeline(stages ...toolkit.M) []toolkit.M {
+	return append(r.matchPipeline(stages), toolkit.M{"$count": "total"})
+}
+
+// hasGroupAggregates checks whether any group level has aggregates.
+func (r *Request) hasGroupAggregates() bool {
+	for _, v := range r.Group {
+		if len(v.aggregates(r.Aggregate)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// sort returns Sort preceded by the group fields.
+func (r *Request) sort() Sort {
+	groupSort := []SortElem{}
+	for _, v := range r.Group {
+		dir := v.Dir
+		if dir != "desc" {
+			dir = "asc"
+		}
+		groupSort = append(groupSort, SortElem{Field: v.Field, Dir: dir})
+	}
+	return r.Sort.Prepend(groupSort...)
+}
+
+func (r *Request) matchPipeline(stages []toolkit.M) []toolkit.M {
+	pipe := append([]toolkit.M{}, stages...)
+	if match := r.Filter.ToAggregateFilter(); match != nil {
+		pipe = append(pipe, toolkit.M{"$match": match})
+	}
+	return pipe
+}
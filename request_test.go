@@ -0,0 +1,187 @@
+package kendohelper_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestRequestOffsetLimit(t *testing.T) {
+	tt := []struct {
+		name    string
+		request kendohelper.Request
+		offset  int
+		limit   int
+	}{
+		{"take and skip", kendohelper.Request{Take: 20, Skip: 40, Page: 3, PageSize: 10}, 40, 20},
+		{"page and pageSize", kendohelper.Request{Page: 3, PageSize: 10}, 20, 10},
+		{"first page", kendohelper.Request{Take: 10, Page: 1, PageSize: 10}, 0, 10},
+		{"no paging", kendohelper.Request{}, 0, 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if offset := tc.request.Offset(); offset != tc.offset {
+				t.Errorf("%v offset should be %v, got %v", tc.name, tc.offset, offset)
+			}
+			if limit := tc.request.Limit(); limit != tc.limit {
+				t.Errorf("%v limit should be %v, got %v", tc.name, tc.limit, limit)
+			}
+		})
+	}
+}
+
+func TestRequestToAggregatePipeline(t *testing.T) {
+	lookup := toolkit.M{"$lookup": toolkit.M{"from": "clients"}}
+	total := toolkit.M{"$project": toolkit.M{
+		"data":  1,
+		"total": toolkit.M{"$ifNull": []interface{}{toolkit.M{"$arrayElemAt": []interface{}{"$total.total", 0}}, 0}},
+	}}
+	count := []toolkit.M{toolkit.M{"$count": "total"}}
+
+	tt := []struct {
+		name     string
+		request  kendohelper.Request
+		stages   []toolkit.M
+		expected []toolkit.M
+	}{
+		{
+			name: "complete",
+			request: kendohelper.Request{
+				Take:   10,
+				Skip:   20,
+				Sort:   kendohelper.Sort{kendohelper.SortElem{"Name", "desc"}},
+				Filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"Age", "gte", 25, nil, ""}}, "and"},
+			},
+			stages: []toolkit.M{lookup},
+			expected: []toolkit.M{
+				lookup,
+				toolkit.M{"$match": toolkit.M{"$and": []toolkit.M{toolkit.M{"Age": toolkit.M{"$gte": 25}}}}},
+				toolkit.M{"$sort": bson.D{{Name: "Name", Value: -1}}},
+				toolkit.M{"$facet": toolkit.M{
+					"data":  []toolkit.M{toolkit.M{"$skip": 20}, toolkit.M{"$limit": 10}},
+					"total": count,
+				}},
+				total,
+			},
+		},
+		{
+			name: "grouped with aggregates",
+			request: kendohelper.Request{
+				Take:      10,
+				Sort:      kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}},
+				Group:     []kendohelper.GroupElem{kendohelper.GroupElem{"City", "desc", nil}},
+				Aggregate: []kendohelper.AggregateElem{kendohelper.AggregateElem{"Age", "average"}, kendohelper.AggregateElem{"Age", "count"}},
+			},
+			expected: []toolkit.M{
+				toolkit.M{"$sort": bson.D{{Name: "City", Value: -1}, {Name: "Name", Value: 1}}},
+				toolkit.M{"$facet": toolkit.M{
+					"data":  []toolkit.M{toolkit.M{"$skip": 0}, toolkit.M{"$limit": 10}},
+					"total": count,
+					"aggregates": []toolkit.M{toolkit.M{"$group": toolkit.M{
+						"_id": nil,
+						"a0":  toolkit.M{"$avg": "$Age"},
+						"a1":  toolkit.M{"$sum": 1},
+					}}},
+					"g0": []toolkit.M{toolkit.M{"$group": toolkit.M{
+						"_id": toolkit.M{"g0": "$City"},
+						"a0":  toolkit.M{"$avg": "$Age"},
+						"a1":  toolkit.M{"$sum": 1},
+					}}},
+				}},
+				toolkit.M{"$project": toolkit.M{
+					"data":       1,
+					"total":      total["$project"].(toolkit.M)["total"],
+					"aggregates": toolkit.M{"$arrayElemAt": []interface{}{"$aggregates", 0}},
+					"groups":     []interface{}{"$g0"},
+				}},
+			},
+		},
+		{
+			name: "subgroups with their own aggregates",
+			request: kendohelper.Request{
+				Group: []kendohelper.GroupElem{
+					kendohelper.GroupElem{"City", "asc", []kendohelper.AggregateElem{kendohelper.AggregateElem{"Age", "max"}}},
+					kendohelper.GroupElem{"Role", "asc", []kendohelper.AggregateElem{kendohelper.AggregateElem{"Name", "count"}}},
+				},
+			},
+			expected: []toolkit.M{
+				toolkit.M{"$sort": bson.D{{Name: "City", Value: 1}, {Name: "Role", Value: 1}}},
+				toolkit.M{"$facet": toolkit.M{
+					"data":  []toolkit.M{toolkit.M{"$skip": 0}},
+					"total": count,
+					"g0": []toolkit.M{toolkit.M{"$group": toolkit.M{
+						"_id": toolkit.M{"g0": "$City"},
+						"a0":  toolkit.M{"$max": "$Age"},
+					}}},
+					"g1": []toolkit.M{toolkit.M{"$group": toolkit.M{
+						"_id": toolkit.M{"g0": "$City", "g1": "$Role"},
+						"a0":  toolkit.M{"$sum": 1},
+					}}},
+				}},
+				toolkit.M{"$project": toolkit.M{
+					"data":   1,
+					"total":  total["$project"].(toolkit.M)["total"],
+					"groups": []interface{}{"$g0", "$g1"},
+				}},
+			},
+		},
+		{
+			name: "empty filter and sort are left out",
+			request: kendohelper.Request{
+				Sort:   kendohelper.Sort{kendohelper.SortElem{"Name", ""}},
+				Filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"Age", "", 25, nil, ""}}, "and"},
+			},
+			expected: []toolkit.M{
+				toolkit.M{"$facet": toolkit.M{
+					"data":  []toolkit.M{toolkit.M{"$skip": 0}},
+					"total": count,
+				}},
+				total,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			pipe := tc.request.ToAggregatePipeline(tc.stages...)
+			if !reflect.DeepEqual(pipe, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, pipe)
+			}
+		})
+	}
+}
+
+func TestRequestToCountPipeline(t *testing.T) {
+	tt := []struct {
+		name     string
+		request  kendohelper.Request
+		expected []toolkit.M
+	}{
+		{
+			name:    "filtered",
+			request: kendohelper.Request{Filter: kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"Name", "eq", "Hari", nil, ""}}, "or"}},
+			expected: []toolkit.M{
+				toolkit.M{"$match": toolkit.M{"$or": []toolkit.M{toolkit.M{"Name": "Hari"}}}},
+				toolkit.M{"$count": "total"},
+			},
+		},
+		{
+			name:     "empty filter",
+			request:  kendohelper.Request{Take: 10},
+			expected: []toolkit.M{toolkit.M{"$count": "total"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			pipe := tc.request.ToCountPipeline()
+			if !reflect.DeepEqual(pipe, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, pipe)
+			}
+		})
+	}
+}
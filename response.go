@@ -0,0 +1,369 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.telerik.com/kendo-ui/api/javascript/data/datasource/configuration/schema
+ * https://docs.telerik.com/kendo-ui/api/javascript/data/datasource/configuration/group
+ */
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// KendoTimeFormat is ISO 8601 with milliseconds, parsed by kendo.parseDate for fields having type "date" in schema's model.
+const KendoTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// GroupElem is element of Kendo's group array.
+type GroupElem struct {
+	Field      string
+	Dir        string
+	Aggregates []AggregateElem
+}
+
+// AggregateElem is element of Kendo's aggregate array, Aggregate is one of "count", "sum", "average", "min" and "max".
+type AggregateElem struct {
+	Field     string
+	Aggregate string
+}
+
+// Aggregates is Kendo's aggregates object: field to aggregate to value, e.g. {"Age": {"sum": 50, "max": 30}}.
+type Aggregates map[string]map[string]interface{}
+
+// Group is Kendo's group object, Items are the rows ([]toolkit.M) or the subgroups ([]Group) when HasSubgroups.
+type Group struct {
+	Field        string      `json:"field"`
+	Value        interface{} `json:"value"`
+	HasSubgroups bool        `json:"hasSubgroups"`
+	Items        interface{} `json:"items"`
+	Aggregates   Aggregates  `json:"aggregates"`
+}
+
+// Response is Kendo's DataSourceResult, configure schema's data, total, aggregates, groups and errors with the same names.
+type Response struct {
+	Data       []toolkit.M `json:"data"`
+	Total      int         `json:"total"`
+	Aggregates Aggregates  `json:"aggregates,omitempty"`
+	Groups     []Group     `json:"groups,omitempty"`
+	Errors     []string    `json:"errors,omitempty"`
+}
+
+// mongoAccumulators maps Kendo's aggregates to Mongo's $group accumulators.
+var mongoAccumulators = map[string]string{
+	"count":   "$sum",
+	"sum":     "$sum",
+	"average": "$avg",
+	"min":     "$min",
+	"max":     "$max",
+}
+
+// NewResponse builds Response from the result of ToAggregatePipeline: time.Time values are formatted with KendoTimeFormat
+// and the rows are grouped when the request is grouped.
+func (r *Request) NewResponse(result FacetResult) Response {
+	data := FormatRows(result.Data)
+	response := Response{Data: data, Total: result.Total}
+	if len(r.Aggregate) != 0 {
+		response.Aggregates = newAggregates(r.Aggregate, result.Aggregates)
+	}
+	if len(r.Group) != 0 {
+		response.Groups = GroupRows(data, r.Group, r.Aggregate)
+		r.setGroupAggregates(response.Groups, result.Groups, nil)
+	}
+	return response
+}
+
+// newAggregates builds Aggregates from values computed by aggregatesStage.
+func newAggregates(aggregates []AggregateElem, values toolkit.M) Aggregates {
+	result := Aggregates{}
+	for i, v := range aggregates {
+		if _, ok := mongoAccumulators[v.Aggregate]; !ok {
+			continue
+		}
+		value := formatValueForKendo(values[aggregateKey(i)])
+		if (v.Aggregate == "count" || v.Aggregate == "sum") && value == nil {
+			value = 0
+		}
+		result.set(v.Field, v.Aggregate, value)
+	}
+	return result
+}
+
+// setGroupAggregates replaces the aggregates of groups computed by GroupRows from the page with those computed
+// by ToAggregatePipeline from the whole groups. keys are the values of the parent groups.
+func (r *Request) setGroupAggregates(groups []Group, results [][]toolkit.M, keys []interface{}) {
+	level := len(keys)
+	if level >= len(results) || level >= len(r.Group) {
+		return
+	}
+	aggregates := r.Group[level].aggregates(r.Aggregate)
+	for i := range groups {
+		groupKeys := append(keys[:level:level], groups[i].Value)
+		for _, values := range results[level] {
+			if isGroupID(values["_id"], groupKeys) {
+				groups[i].Aggregates = newAggregates(aggregates, values)
+				break
+			}
+		}
+		if subgroups, ok := groups[i].Items.([]Group); ok {
+			r.setGroupAggregates(subgroups, results, groupKeys)
+		}
+	}
+}
+
+// isGroupID checks whether id, the _id of a group computed by aggregatesStage, has keys as its values.
+func isGroupID(id interface{}, keys []interface{}) bool {
+	m, ok := formatValueForKendo(id).(toolkit.M)
+	if !ok || len(m) > len(keys) {
+		return false
+	}
+	for i := range keys {
+		if !reflect.DeepEqual(m[groupKey(i)], keys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewErrorResponse builds Response triggering DataSource's error event with the messages of errs,
+// each violation of *PolicyError has its own message.
+func NewErrorResponse(errs ...error) Response {
+	response := Response{Data: []toolkit.M{}}
+	for _, err := range errs {
+		if err, ok := err.(*PolicyError); ok {
+			for _, v := range err.Violations {
+				response.Errors = append(response.Errors, "kendohelper: "+v.String())
+			}
+			continue
+		}
+		response.Errors = append(response.Errors, err.Error())
+	}
+	if len(response.Errors) == 0 {
+		response.Errors = []string{"kendohelper: unknown error"}
+	}
+	return response
+}
+
+// FormatRows returns brand new rows having time.Time values, nested ones included, formatted with KendoTimeFormat.
+func FormatRows(rows []toolkit.M) []toolkit.M {
+	formatted := make([]toolkit.M, len(rows))
+	for i := range rows {
+		formatted[i] = formatValueForKendo(rows[i]).(toolkit.M)
+	}
+	return formatted
+}
+
+// GroupRows groups rows into nested Kendo's groups following groups' order, rows must already be sorted by the group fields
+// (ToAggregatePipeline does). Aggregates of each group are computed from its rows, using the group's aggregates
+// or aggregates when the group has none. Rows of a page may not cover whole groups, NewResponse replaces these aggregates
+// with those computed by ToAggregatePipeline.
+func GroupRows(rows []toolkit.M, groups []GroupElem, aggregates []AggregateElem) []Group {
+	result := []Group{}
+	if len(groups) == 0 {
+		return result
+	}
+	elem := groups[0]
+	groupAggregates := elem.aggregates(aggregates)
+	for start := 0; start < len(rows); {
+		value, _ := lookupField(rows[start], elem.Field)
+		end := start + 1
+		for end < len(rows) {
+			next, _ := lookupField(rows[end], elem.Field)
+			if !reflect.DeepEqual(next, value) {
+				break
+			}
+			end++
+		}
+
+		group := Group{
+			Field:      elem.Field,
+			Value:      value,
+			Items:      rows[start:end],
+			Aggregates: ComputeAggregates(rows[start:end], groupAggregates),
+		}
+		if len(groups) > 1 {
+			group.HasSubgroups = true
+			group.Items = GroupRows(rows[start:end], groups[1:], aggregates)
+		}
+		result = append(result, group)
+		start = end
+	}
+	return result
+}
+
+// ComputeAggregates computes aggregates of rows in memory, "sum" and "average" only count numbers.
+func ComputeAggregates(rows []toolkit.M, aggregates []AggregateElem) Aggregates {
+	result := Aggregates{}
+	for _, v := range aggregates {
+		switch v.Aggregate {
+		case "count":
+			result.set(v.Field, v.Aggregate, len(rows))
+		case "sum", "average":
+			sum, count := 0.0, 0
+			for _, row := range rows {
+				value, _ := lookupField(row, v.Field)
+				if number, ok := toFloat64(value); ok {
+					sum += number
+					count++
+				}
+			}
+			if v.Aggregate == "sum" {
+				result.set(v.Field, v.Aggregate, sum)
+			} else if count != 0 {
+				result.set(v.Field, v.Aggregate, sum/float64(count))
+			} else {
+				result.set(v.Field, v.Aggregate, nil)
+			}
+		case "min", "max":
+			var extreme interface{}
+			for _, row := range rows {
+				value, _ := lookupField(row, v.Field)
+				if value == nil {
+					continue
+				}
+				if extreme == nil {
+					extreme = value
+					continue
+				}
+				if c, ok := compareValues(value, extreme); ok && (c < 0 && v.Aggregate == "min" || c > 0 && v.Aggregate == "max") {
+					extreme = value
+				}
+			}
+			result.set(v.Field, v.Aggregate, extreme)
+		}
+	}
+	return result
+}
+
+// aggregates returns the aggregates of the group, or else aggregates.
+func (g GroupElem) aggregates(aggregates []AggregateElem) []AggregateElem {
+	if len(g.Aggregates) != 0 {
+		return g.Aggregates
+	}
+	return aggregates
+}
+
+func (a Aggregates) set(field, aggregate string, value interface{}) {
+	if a[field] == nil {
+		a[field] = map[string]interface{}{}
+	}
+	a[field][aggregate] = value
+}
+
+// aggregatesStage returns $group by id computing aggregates, keyed by their index since fields may have dots.
+func aggregatesStage(id interface{}, aggregates []AggregateElem) toolkit.M {
+	group := toolkit.M{"_id": id}
+	for i, v := range aggregates {
+		accumulator, ok := mongoAccumulators[v.Aggregate]
+		if !ok {
+			continue
+		}
+		if v.Aggregate == "count" {
+			group[aggregateKey(i)] = toolkit.M{accumulator: 1}
+			continue
+		}
+		group[aggregateKey(i)] = toolkit.M{accumulator: "$" + v.Field}
+	}
+	return toolkit.M{"$group": group}
+}
+
+func aggregateKey(i int) string {
+	return "a" + strconv.Itoa(i)
+}
+
+func groupKey(i int) string {
+	return "g" + strconv.Itoa(i)
+}
+
+func formatValueForKendo(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(KendoTimeFormat)
+	case toolkit.M:
+		return formatMap(v)
+	case bson.M:
+		return formatMap(v)
+	case map[string]interface{}:
+		return formatMap(v)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = formatValueForKendo(v[i])
+		}
+		return values
+	}
+	return value
+}
+
+func formatMap(m map[string]interface{}) toolkit.M {
+	formatted := toolkit.M{}
+	for k, v := range m {
+		formatted[k] = formatValueForKendo(v)
+	}
+	return formatted
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bson.Decimal128:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// compareValues compares numbers (exactly, see compareNumbers), strings, time.Time and bool, ok is false when a and b can't be compared.
+func compareValues(a, b interface{}) (c int, ok bool) {
+	if _, ok := toFloat64(a); ok {
+		return compareNumbers(a, b)
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	case time.Time:
+		y, ok := b.(time.Time)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(x.Before(y), x.After(y)), true
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(!x && y, x && !y), true
+	}
+	return 0, false
+}
+
+func compareOrdered(less, greater bool) int {
+	if less {
+		return -1
+	}
+	if greater {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,172 @@
+package kendohelper_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestRequestNewResponse(t *testing.T) {
+	request := kendohelper.Request{
+		Group: []kendohelper.GroupElem{
+			kendohelper.GroupElem{"City", "asc", nil},
+			kendohelper.GroupElem{"Role", "asc", []kendohelper.AggregateElem{kendohelper.AggregateElem{"Name", "count"}}},
+		},
+		Aggregate: []kendohelper.AggregateElem{
+			kendohelper.AggregateElem{"Age", "sum"},
+			kendohelper.AggregateElem{"Age", "max"},
+			kendohelper.AggregateElem{"Age", "median"},
+		},
+	}
+	joined := time.Date(2019, 01, 02, 03, 04, 05, 6000000, time.UTC)
+	result := kendohelper.FacetResult{
+		Data: []toolkit.M{
+			toolkit.M{"Name": "Hari", "City": "Jakarta", "Role": "dev", "Age": 25, "Joined": joined},
+			toolkit.M{"Name": "Surya", "City": "Jakarta", "Role": "dev", "Age": 27.5, "Profile": bson.M{"Joined": joined}},
+			toolkit.M{"Name": "Radit", "City": "Jakarta", "Role": "ops", "Age": 30},
+			toolkit.M{"Name": "Dewa", "City": "Bandung", "Role": "ops", "Age": 22},
+		},
+		Total:      42,
+		Aggregates: toolkit.M{"_id": nil, "a0": 1000, "a1": 60},
+	}
+
+	response := request.NewResponse(result)
+	if response.Total != 42 {
+		t.Errorf("total should be 42, got %v", response.Total)
+	}
+	if response.Data[0]["Joined"] != "2019-01-02T03:04:05.006Z" || response.Data[1]["Profile"].(toolkit.M)["Joined"] != "2019-01-02T03:04:05.006Z" {
+		t.Errorf("time should be formatted, got %v", response.Data)
+	}
+	if result.Data[0]["Joined"] != joined {
+		t.Errorf("result should not be changed, got %v", result.Data[0])
+	}
+	expectedAggregates := kendohelper.Aggregates{"Age": {"sum": 1000, "max": 60}}
+	if !reflect.DeepEqual(response.Aggregates, expectedAggregates) {
+		t.Errorf("aggregates should be %v, got %v", expectedAggregates, response.Aggregates)
+	}
+
+	expectedGroups := []kendohelper.Group{
+		kendohelper.Group{
+			Field:        "City",
+			Value:        "Jakarta",
+			HasSubgroups: true,
+			Items: []kendohelper.Group{
+				kendohelper.Group{"Role", "dev", false, response.Data[0:2], kendohelper.Aggregates{"Name": {"count": 2}}},
+				kendohelper.Group{"Role", "ops", false, response.Data[2:3], kendohelper.Aggregates{"Name": {"count": 1}}},
+			},
+			Aggregates: kendohelper.Aggregates{"Age": {"sum": 82.5, "max": 30}},
+		},
+		kendohelper.Group{
+			Field:        "City",
+			Value:        "Bandung",
+			HasSubgroups: true,
+			Items: []kendohelper.Group{
+				kendohelper.Group{"Role", "ops", false, response.Data[3:4], kendohelper.Aggregates{"Name": {"count": 1}}},
+			},
+			Aggregates: kendohelper.Aggregates{"Age": {"sum": 22.0, "max": 22}},
+		},
+	}
+	if !reflect.DeepEqual(response.Groups, expectedGroups) {
+		t.Errorf("groups should be %v, got %v", expectedGroups, response.Groups)
+	}
+}
+
+func TestRequestNewResponseGroupAggregates(t *testing.T) {
+	request := kendohelper.Request{
+		Take: 2,
+		Group: []kendohelper.GroupElem{
+			kendohelper.GroupElem{"Joined", "asc", nil},
+			kendohelper.GroupElem{"Role", "asc", []kendohelper.AggregateElem{kendohelper.AggregateElem{"Name", "count"}}},
+		},
+		Aggregate: []kendohelper.AggregateElem{kendohelper.AggregateElem{"Age", "sum"}},
+	}
+	joined := time.Date(2019, 01, 02, 00, 00, 00, 00, time.UTC)
+	result := kendohelper.FacetResult{
+		Data: []toolkit.M{
+			toolkit.M{"Name": "Hari", "Joined": joined, "Role": "dev", "Age": 25},
+			toolkit.M{"Name": "Surya", "Joined": joined, "Role": "dev", "Age": 27},
+		},
+		Total:      5,
+		Aggregates: toolkit.M{"a0": 130},
+		Groups: [][]toolkit.M{
+			[]toolkit.M{
+				toolkit.M{"_id": bson.M{"g0": joined}, "a0": 130},
+			},
+			[]toolkit.M{
+				toolkit.M{"_id": bson.M{"g0": joined, "g1": "ops"}, "a0": 2},
+				toolkit.M{"_id": bson.M{"g0": joined, "g1": "dev"}, "a0": 3},
+			},
+		},
+	}
+
+	response := request.NewResponse(result)
+	expected := []kendohelper.Group{
+		kendohelper.Group{
+			Field:        "Joined",
+			Value:        "2019-01-02T00:00:00.000Z",
+			HasSubgroups: true,
+			Items: []kendohelper.Group{
+				kendohelper.Group{"Role", "dev", false, response.Data, kendohelper.Aggregates{"Name": {"count": 3}}},
+			},
+			Aggregates: kendohelper.Aggregates{"Age": {"sum": 130}},
+		},
+	}
+	if !reflect.DeepEqual(response.Groups, expected) {
+		t.Errorf("groups should be %v, got %v", expected, response.Groups)
+	}
+}
+
+func TestRequestNewResponseEmpty(t *testing.T) {
+	request := kendohelper.Request{Aggregate: []kendohelper.AggregateElem{
+		kendohelper.AggregateElem{"Age", "count"},
+		kendohelper.AggregateElem{"Age", "average"},
+	}}
+	data, err := json.Marshal(request.NewResponse(kendohelper.FacetResult{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"data":[],"total":0,"aggregates":{"Age":{"average":null,"count":0}}}`
+	if string(data) != expected {
+		t.Errorf("response should be %v, got %v", expected, string(data))
+	}
+}
+
+func TestComputeAggregates(t *testing.T) {
+	rows := []toolkit.M{
+		toolkit.M{"Name": "Hari", "Age": 25, "Joined": time.Date(2019, 01, 02, 00, 00, 00, 00, time.UTC)},
+		toolkit.M{"Name": "Radit", "Age": nil, "Joined": time.Date(2018, 01, 02, 00, 00, 00, 00, time.UTC)},
+		toolkit.M{"Name": "Surya", "Age": int64(30)},
+	}
+	aggregates := kendohelper.ComputeAggregates(rows, []kendohelper.AggregateElem{
+		kendohelper.AggregateElem{"Age", "average"},
+		kendohelper.AggregateElem{"Age", "min"},
+		kendohelper.AggregateElem{"Name", "max"},
+		kendohelper.AggregateElem{"Joined", "min"},
+		kendohelper.AggregateElem{"Joined", "count"},
+	})
+	expected := kendohelper.Aggregates{
+		"Age":    {"average": 27.5, "min": 25},
+		"Name":   {"max": "Surya"},
+		"Joined": {"min": time.Date(2018, 01, 02, 00, 00, 00, 00, time.UTC), "count": 3},
+	}
+	if !reflect.DeepEqual(aggregates, expected) {
+		t.Errorf("aggregates should be %v, got %v", expected, aggregates)
+	}
+}
+
+func TestNewErrorResponse(t *testing.T) {
+	data, err := json.Marshal(kendohelper.NewErrorResponse(errors.New("kendohelper: filter: unknown key")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"data":[],"total":0,"errors":["kendohelper: filter: unknown key"]}`
+	if string(data) != expected {
+		t.Errorf("response should be %v, got %v", expected, string(data))
+	}
+}
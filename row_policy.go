@@ -0,0 +1,127 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"context"
+	"strconv"
+)
+
+// Param is a placeholder of the value of RowPolicy's rules, bound from the context (see WithParams),
+// e.g. Filter{"tenant", "eq", Param("tenant"), nil, ""}.
+type Param string
+
+type paramsContextKey struct{}
+
+// WithParams returns a copy of ctx carrying params (e.g. {"tenant": "A", "owner": "hari"}) bound to RowPolicy's rules.
+// Params already carried by ctx are kept unless they're overridden.
+func WithParams(ctx context.Context, params map[string]interface{}) context.Context {
+	merged := map[string]interface{}{}
+	for k, v := range ParamsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, paramsContextKey{}, merged)
+}
+
+// ParamsFromContext returns the params carried by ctx.
+func ParamsFromContext(ctx context.Context) map[string]interface{} {
+	params, _ := ctx.Value(paramsContextKey{}).(map[string]interface{})
+	return params
+}
+
+// RowPolicyError is returned by RowPolicy's Apply when a rule can't be applied, the query must not be run.
+type RowPolicyError struct {
+	Message string
+}
+
+func (e *RowPolicyError) Error() string {
+	return "kendohelper: row policy: " + e.Message
+}
+
+// RowPolicy constrains every query by the caller's permissions (tenant, region, owner, ...): its Rules are bound from the context
+// and joined with "and" to the user's filter, so the user's "or" filters can't bypass them.
+// Apply it before converting the filter (ToDBOXFilter, ToAggregateFilter, etc.), on database fields (after aliasing).
+// It fails closed: a param which isn't bound (or is nil), or a rule which would be ignored by the converters is an error.
+type RowPolicy struct {
+	Rules []Filter
+}
+
+// NewRowPolicy creates RowPolicy of rules.
+func NewRowPolicy(rules ...Filter) *RowPolicy {
+	return &RowPolicy{Rules: rules}
+}
+
+// Apply returns a brand new Filter matching the rows matched by filter and all rules bound from ctx's params.
+func (p *RowPolicy) Apply(ctx context.Context, filter Filter) (Filter, error) {
+	params := ParamsFromContext(ctx)
+	filters := []Filter{filter}
+	for i := range p.Rules {
+		rule := p.Rules[i].DeepClone()
+		if err := bindRule(&rule, params, "rules["+strconv.Itoa(i)+"]"); err != nil {
+			return Filter{}, err
+		}
+		filters = append(filters, rule)
+	}
+	return And(filters...), nil
+}
+
+// ApplyRequest applies the rules to the filter of request.
+func (p *RowPolicy) ApplyRequest(ctx context.Context, request *Request) error {
+	filter, err := p.Apply(ctx, request.Filter)
+	if err != nil {
+		return err
+	}
+	request.Filter = filter
+	return nil
+}
+
+// bindRule replaces the params of rule in place and makes sure none of its filters is ignored.
+func bindRule(rule *Filter, params map[string]interface{}, path string) error {
+	if len(rule.Filters) == 0 {
+		value, err := bindValue(rule.Value, params, path)
+		if err != nil {
+			return err
+		}
+		rule.Value = value
+		if _, ok := rule.canonical(); !ok {
+			return &RowPolicyError{path + ": operator " + strconv.Quote(rule.Operator) + " having value of " + describeValue(value) + " is ignored"}
+		}
+		return nil
+	}
+	if rule.Logic != "and" && rule.Logic != "or" && rule.Logic != "not" {
+		return &RowPolicyError{path + ": logic " + strconv.Quote(rule.Logic) + " is ignored"}
+	}
+	for i := range rule.Filters {
+		if err := bindRule(&rule.Filters[i], params, path+".filters["+strconv.Itoa(i)+"]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bindValue(value interface{}, params map[string]interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case Param:
+		bound, ok := params[string(v)]
+		if !ok || bound == nil {
+			return nil, &RowPolicyError{path + ": param " + strconv.Quote(string(v)) + " is not bound"}
+		}
+		return bound, nil
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			bound, err := bindValue(v[i], params, path)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = bound
+		}
+		return values, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,131 @@
+package kendohelper_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+func TestRowPolicyApply(t *testing.T) {
+	policy := kendohelper.NewRowPolicy(
+		kendohelper.Filter{"tenant", "eq", kendohelper.Param("tenant"), nil, ""},
+		kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"owner", "eq", kendohelper.Param("user"), nil, ""},
+			kendohelper.Filter{"public", "eq", true, nil, ""},
+		}, "or"},
+	)
+	ctx := kendohelper.WithParams(context.Background(), map[string]interface{}{"tenant": "A"})
+	ctx = kendohelper.WithParams(ctx, map[string]interface{}{"user": "hari"})
+	rules := []kendohelper.Filter{
+		kendohelper.Filter{"tenant", "eq", "A", nil, ""},
+		kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"owner", "eq", "hari", nil, ""},
+			kendohelper.Filter{"public", "eq", true, nil, ""},
+		}, "or"},
+	}
+	userOr := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"name", "eq", "Surya", nil, ""},
+		kendohelper.Filter{"tenant", "eq", "B", nil, ""},
+	}, "or"}
+
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected kendohelper.Filter
+	}{
+		{"empty filter", kendohelper.Filter{}, kendohelper.Filter{"", "", nil, rules, "and"}},
+		{"user's or can't bypass", userOr, kendohelper.Filter{"", "", nil, append([]kendohelper.Filter{userOr}, rules...), "and"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := policy.Apply(ctx, tc.filter)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(filter, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected.Expression(), filter.Expression())
+			}
+		})
+	}
+
+	if _, ok := policy.Rules[0].Value.(kendohelper.Param); !ok {
+		t.Errorf("rules shouldn't be changed, got %v", policy.Rules[0].Value)
+	}
+}
+
+func TestRowPolicyApplyError(t *testing.T) {
+	tt := []struct {
+		name     string
+		rule     kendohelper.Filter
+		params   map[string]interface{}
+		expected string
+	}{
+		{"param not bound", kendohelper.Filter{"tenant", "eq", kendohelper.Param("tenant"), nil, ""}, nil,
+			`kendohelper: row policy: rules[0]: param "tenant" is not bound`},
+		{"nil param", kendohelper.Filter{"tenant", "eq", kendohelper.Param("tenant"), nil, ""}, map[string]interface{}{"tenant": nil},
+			`kendohelper: row policy: rules[0]: param "tenant" is not bound`},
+		{"param in values", kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"region", "eq", "ID", nil, ""},
+			kendohelper.Filter{"region", "eq", kendohelper.Param("region"), nil, ""},
+		}, "or"}, nil, `kendohelper: row policy: rules[0].filters[1]: param "region" is not bound`},
+		{"ignored operator", kendohelper.Filter{"tenant", "equals", "A", nil, ""}, nil,
+			`kendohelper: row policy: rules[0]: operator "equals" having value of "A" is ignored`},
+		{"ignored value", kendohelper.Filter{"tenant", "startswith", kendohelper.Param("tenant"), nil, ""}, map[string]interface{}{"tenant": 1},
+			`kendohelper: row policy: rules[0]: operator "startswith" having value of 1 is ignored`},
+		{"ignored logic", kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"tenant", "eq", "A", nil, ""}}, "xor"}, nil,
+			`kendohelper: row policy: rules[0]: logic "xor" is ignored`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := kendohelper.WithParams(context.Background(), tc.params)
+			_, err := kendohelper.NewRowPolicy(tc.rule).Apply(ctx, kendohelper.Filter{})
+			if _, ok := err.(*kendohelper.RowPolicyError); !ok || err.Error() != tc.expected {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestHandlerRowPolicy(t *testing.T) {
+	handler := &kendohelper.Handler{
+		Fields:    map[string]string{"name": "fullname"},
+		RowPolicy: kendohelper.NewRowPolicy(kendohelper.Filter{"tenant", "eq", kendohelper.Param("tenant"), nil, ""}),
+		Data: func(ctx context.Context, request kendohelper.Request) (kendohelper.Response, error) {
+			return kendohelper.Response{Data: []toolkit.M{toolkit.M{"filter": request.Filter.Expression()}}, Total: 1}, nil
+		},
+	}
+	query := "/grid?filter[logic]=or&filter[filters][0][field]=name&filter[filters][0][operator]=eq&filter[filters][0][value]=Hari"
+
+	tt := []struct {
+		name   string
+		params map[string]interface{}
+		status int
+		body   string
+	}{
+		{"constrained", map[string]interface{}{"tenant": "A"}, http.StatusOK, `{"data":[{"filter":"fullname = \"Hari\" and tenant = \"A\""}],"total":1}`},
+		{"forbidden", nil, http.StatusForbidden, `{"data":[],"total":0,"errors":["Forbidden"]}`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", query, nil)
+			r = r.WithContext(kendohelper.WithParams(r.Context(), tc.params))
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, r)
+			if recorder.Code != tc.status {
+				t.Errorf("%v status should be %v, got %v", tc.name, tc.status, recorder.Code)
+			}
+			if body := strings.TrimSpace(recorder.Body.String()); body != tc.body {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.body, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.mongodb.com/manual/reference/bson-type-comparison-order/#numeric-types
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// FieldType is the type of the values of a field in the database.
+type FieldType string
+
+const (
+	FieldInt64   FieldType = "int64"
+	FieldDouble  FieldType = "double"
+	FieldDecimal FieldType = "decimal"
+)
+
+// Schema maps database fields to their type, so filter values (json.Number, float64 or strings from the query string)
+// are converted exactly, e.g. {"price": kendohelper.FieldDecimal, "id": kendohelper.FieldInt64}.
+type Schema map[string]FieldType
+
+// Convert returns a brand new Filter whose values of fields listed in the schema are converted: int64, float64 or bson.Decimal128.
+// Values losing their precision (e.g. float64 beyond 2^53 into int64) or which can't be converted are *DecodeError.
+// Decode filters with UnmarshalJSON (json.Number) to keep big numbers exact.
+func (s Schema) Convert(filter Filter) (Filter, error) {
+	filter = filter.DeepClone()
+	err := filter.WalkErr(func(filter Filter, info WalkInfo) (Filter, WalkAction, error) {
+		fieldType, ok := s[filter.Field]
+		if !info.Leaf || !ok || unaryOperators[filter.Operator] {
+			return filter, WalkContinue, nil
+		}
+		value, err := convertValue(fieldType, filter.Value)
+		if err != nil {
+			return filter, WalkStop, &DecodeError{walkPath(info.Path), "value of " + strconv.Quote(filter.Field) + " " + err.Error()}
+		}
+		filter.Value = value
+		return filter, WalkContinue, nil
+	}, nil)
+	if err != nil {
+		return Filter{}, err
+	}
+	return filter, nil
+}
+
+// SQLValue converts value of field to a bind value of SQL: int64, float64 or the exact text of the decimal (e.g. "19.99") for NUMERIC.
+// Fields not listed in the schema are kept as is.
+func (s Schema) SQLValue(field string, value interface{}) (interface{}, error) {
+	fieldType, ok := s[field]
+	if !ok {
+		return value, nil
+	}
+	converted, err := convertValue(fieldType, value)
+	if err != nil {
+		return nil, &DecodeError{field, "must be " + string(fieldType)}
+	}
+	d, ok := converted.(bson.Decimal128)
+	if !ok {
+		return converted, nil
+	}
+	n, ok := toNumber(d)
+	if !ok || n.rat == nil {
+		return nil, &DecodeError{field, "must be a finite decimal"}
+	}
+	return decimalText(n.rat), nil
+}
+
+func convertValue(fieldType FieldType, value interface{}) (interface{}, error) {
+	if values, ok := value.([]interface{}); ok {
+		converted := make([]interface{}, len(values))
+		for i := range values {
+			v, err := convertValue(fieldType, values[i])
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = v
+		}
+		return converted, nil
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	var text string
+	switch v := value.(type) {
+	case json.Number:
+		text = v.String()
+	case string:
+		text = v
+	case float64:
+		if fieldType == FieldInt64 && (v != math.Trunc(v) || math.Abs(v) > 1<<53) {
+			return nil, errors.New("loses precision as int64")
+		}
+		text = strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		text = strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case int:
+		text = strconv.Itoa(v)
+	case int32:
+		text = strconv.FormatInt(int64(v), 10)
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	case bson.Decimal128:
+		text = v.String()
+	default:
+		return nil, errors.New("must be " + string(fieldType))
+	}
+
+	switch fieldType {
+	case FieldInt64:
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return i, nil
+		}
+		// e.g. 1e3 or 5.0
+		if n, ok := new(big.Rat).SetString(text); ok && n.IsInt() && n.Num().IsInt64() {
+			return n.Num().Int64(), nil
+		}
+	case FieldDouble:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+	case FieldDecimal:
+		if d, err := bson.ParseDecimal128(text); err == nil {
+			return d, nil
+		}
+	}
+	return nil, errors.New("must be " + string(fieldType))
+}
+
+// number is an exact number, rat is nil for NaN and infinities (kind).
+type number struct {
+	kind int // -2 NaN, -1 -Inf, 0 finite, 1 +Inf
+	rat  *big.Rat
+}
+
+// toNumber converts numbers (bson.Decimal128 included) exactly.
+func toNumber(value interface{}) (number, bool) {
+	switch v := value.(type) {
+	case int:
+		return number{rat: new(big.Rat).SetInt64(int64(v))}, true
+	case int32:
+		return number{rat: new(big.Rat).SetInt64(int64(v))}, true
+	case int64:
+		return number{rat: new(big.Rat).SetInt64(v)}, true
+	case uint64:
+		return number{rat: new(big.Rat).SetUint64(v)}, true
+	case float32:
+		return floatNumber(float64(v)), true
+	case float64:
+		return floatNumber(v), true
+	case bson.Decimal128:
+		switch text := v.String(); text {
+		case "NaN":
+			return number{kind: -2}, true
+		case "-Inf":
+			return number{kind: -1}, true
+		case "Inf":
+			return number{kind: 1}, true
+		default:
+			rat, ok := new(big.Rat).SetString(text)
+			return number{rat: rat}, ok
+		}
+	}
+	return number{}, false
+}
+
+func floatNumber(f float64) number {
+	switch {
+	case math.IsNaN(f):
+		return number{kind: -2}
+	case math.IsInf(f, -1):
+		return number{kind: -1}
+	case math.IsInf(f, 1):
+		return number{kind: 1}
+	}
+	return number{rat: new(big.Rat).SetFloat64(f)}
+}
+
+// compareNumbers compares numbers of any type exactly the way mongo does: NaN equals NaN and is less than any other number.
+func compareNumbers(a, b interface{}) (int, bool) {
+	// fast path: both are exact as float64
+	if x, ok := exactFloat64(a); ok {
+		if y, ok := exactFloat64(b); ok && !math.IsNaN(x) && !math.IsNaN(y) {
+			return compareOrdered(x < y, x > y), true
+		}
+	}
+	x, ok := toNumber(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := toNumber(b)
+	if !ok {
+		return 0, false
+	}
+	if x.rat == nil || y.rat == nil {
+		return compareOrdered(x.kind < y.kind, x.kind > y.kind), true
+	}
+	return x.rat.Cmp(y.rat), true
+}
+
+func exactFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), v >= -1<<53 && v <= 1<<53
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), v >= -1<<53 && v <= 1<<53
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// decimalText formats a rational number having a finite decimal expansion without exponent, e.g. 19.99.
+func decimalText(rat *big.Rat) string {
+	digits := 0
+	ten := big.NewRat(10, 1)
+	for x := new(big.Rat).Set(rat); !x.IsInt(); x.Mul(x, ten) {
+		digits++
+	}
+	return rat.FloatString(digits)
+}
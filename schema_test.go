@@ -0,0 +1,135 @@
+package kendohelper_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var testSchema = kendohelper.Schema{
+	"id":    kendohelper.FieldInt64,
+	"price": kendohelper.FieldDecimal,
+	"rate":  kendohelper.FieldDouble,
+}
+
+func mustDecimal(s string) bson.Decimal128 {
+	d, err := bson.ParseDecimal128(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestSchemaConvert(t *testing.T) {
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected kendohelper.Filter
+		err      string
+	}{
+		{"big int64 from json.Number", kendohelper.Filter{"id", "eq", json.Number("9007199254740993"), nil, ""},
+			kendohelper.Filter{"id", "eq", int64(9007199254740993), nil, ""}, ""},
+		{"int64 from string", kendohelper.Filter{"id", "gt", "42", nil, ""},
+			kendohelper.Filter{"id", "gt", int64(42), nil, ""}, ""},
+		{"int64 from exponent", kendohelper.Filter{"id", "gt", json.Number("1e3"), nil, ""},
+			kendohelper.Filter{"id", "gt", int64(1000), nil, ""}, ""},
+		{"decimal from json.Number", kendohelper.Filter{"price", "gte", json.Number("19.99"), nil, ""},
+			kendohelper.Filter{"price", "gte", mustDecimal("19.99"), nil, ""}, ""},
+		{"decimal from float64", kendohelper.Filter{"price", "gte", 0.1, nil, ""},
+			kendohelper.Filter{"price", "gte", mustDecimal("0.1"), nil, ""}, ""},
+		{"double from string", kendohelper.Filter{"rate", "lt", "0.5", nil, ""},
+			kendohelper.Filter{"rate", "lt", 0.5, nil, ""}, ""},
+		{"unary operator is kept", kendohelper.Filter{"price", "isnull", "", nil, ""},
+			kendohelper.Filter{"price", "isnull", "", nil, ""}, ""},
+		{"untyped field is kept", kendohelper.Filter{"name", "eq", json.Number("1"), nil, ""},
+			kendohelper.Filter{"name", "eq", json.Number("1"), nil, ""}, ""},
+		{"values", kendohelper.Filter{"", "", nil, []kendohelper.Filter{kendohelper.Filter{"id", "eq", []interface{}{json.Number("1"), "2"}, nil, ""}}, "or"},
+			kendohelper.Filter{"", "", nil, []kendohelper.Filter{kendohelper.Filter{"id", "eq", []interface{}{int64(1), int64(2)}, nil, ""}}, "or"}, ""},
+		{"float64 loses precision", kendohelper.Filter{"id", "eq", float64(1 << 60), nil, ""},
+			kendohelper.Filter{}, `kendohelper: filter: value of "id" loses precision as int64`},
+		{"invalid", kendohelper.Filter{"", "", nil, []kendohelper.Filter{kendohelper.Filter{"price", "eq", "cheap", nil, ""}}, "and"},
+			kendohelper.Filter{}, `kendohelper: filter.filters[0]: value of "price" must be decimal`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := testSchema.Convert(tc.filter)
+			if tc.err != "" {
+				if _, ok := err.(*kendohelper.DecodeError); !ok || err.Error() != tc.err {
+					t.Errorf("%v error should be %v, got %v", tc.name, tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(filter, tc.expected) {
+				t.Errorf("%v should be %#v, got %#v", tc.name, tc.expected, filter)
+			}
+		})
+	}
+}
+
+func TestSchemaSQLValue(t *testing.T) {
+	tt := []struct {
+		name     string
+		field    string
+		value    interface{}
+		expected interface{}
+	}{
+		{"numeric", "price", json.Number("19.990"), "19.99"},
+		{"numeric having exponent", "price", json.Number("5e3"), "5000"},
+		{"int64", "id", json.Number("9007199254740993"), int64(9007199254740993)},
+		{"untyped", "name", "Hari", "Hari"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := testSchema.SQLValue(tc.field, tc.value)
+			if err != nil || value != tc.expected {
+				t.Errorf("%v should be %v, got %v (%v)", tc.name, tc.expected, value, err)
+			}
+		})
+	}
+	if _, err := testSchema.SQLValue("price", "NaN"); err == nil {
+		t.Errorf("NaN should be an error")
+	}
+}
+
+func TestExactNumericMatch(t *testing.T) {
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		row      toolkit.M
+		expected bool
+	}{
+		{"int64 beyond 2^53", kendohelper.Filter{"id", "eq", int64(9007199254740993), nil, ""}, toolkit.M{"id": int64(9007199254740992)}, false},
+		{"int64 beyond 2^53 vs float64", kendohelper.Filter{"id", "lt", float64(1 << 53), nil, ""}, toolkit.M{"id": int64(1<<53 + 1)}, false},
+		{"decimal vs float64", kendohelper.Filter{"price", "eq", 0.1, nil, ""}, toolkit.M{"price": mustDecimal("0.1")}, false},
+		{"decimal vs decimal", kendohelper.Filter{"price", "eq", mustDecimal("19.990"), nil, ""}, toolkit.M{"price": mustDecimal("19.99")}, true},
+		{"decimal vs int", kendohelper.Filter{"price", "gt", 19, nil, ""}, toolkit.M{"price": mustDecimal("19.01")}, true},
+		{"NaN equals NaN", kendohelper.Filter{"rate", "eq", math.NaN(), nil, ""}, toolkit.M{"rate": mustDecimal("NaN")}, true},
+		{"NaN is less than any number", kendohelper.Filter{"rate", "lt", math.Inf(-1), nil, ""}, toolkit.M{"rate": math.NaN()}, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if matched := tc.filter.Match(tc.row); matched != tc.expected {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, matched)
+			}
+		})
+	}
+
+	rows := []toolkit.M{toolkit.M{"v": mustDecimal("2.5")}, toolkit.M{"v": int64(1<<53 + 1)}, toolkit.M{"v": 2}, toolkit.M{"v": float64(1 << 53)}}
+	sort := kendohelper.Sort{kendohelper.SortElem{"v", "asc"}}
+	sort.SortRows(rows)
+	expected := []toolkit.M{toolkit.M{"v": 2}, toolkit.M{"v": mustDecimal("2.5")}, toolkit.M{"v": float64(1 << 53)}, toolkit.M{"v": int64(1<<53 + 1)}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("rows should be sorted exactly, got %v", rows)
+	}
+}
@@ -90,3 +90,26 @@ func (s *Sort) HasField(fields ...string) bool {
 	}
 	return false
 }
+
+// Prepend returns a brand new Sort with sortElems placed before s, sortElem on the same field is only kept once.
+func (s *Sort) Prepend(sortElems ...SortElem) Sort {
+	sort := Sort{}
+	for _, v := range append(append([]SortElem{}, sortElems...), *s...) {
+		if !sort.HasField(v.Field) {
+			sort = append(sort, v)
+		}
+	}
+	return sort
+}
+
+// WithTieBreaker returns a brand new Sort ended by ascending field, unless s is already sorted by field.
+// Use a unique field (e.g. "_id") to make the order deterministic across pages.
+func (s *Sort) WithTieBreaker(field string) Sort {
+	sort := s.DeepCopy()
+	for _, v := range sort {
+		if v.Field == field && (v.Dir == "asc" || v.Dir == "desc") {
+			return sort
+		}
+	}
+	return append(sort, SortElem{Field: field, Dir: "asc"})
+}
@@ -308,3 +308,69 @@ func TestSortHasField(t *testing.T) {
 		}
 	}
 }
+
+func TestSortPrepend(t *testing.T) {
+	sort := kendohelper.Sort{
+		kendohelper.SortElem{"Name", "asc"},
+		kendohelper.SortElem{"Age", "desc"},
+	}
+	result := sort.Prepend(kendohelper.SortElem{"Pinned", "desc"}, kendohelper.SortElem{"Age", "asc"})
+	expected := kendohelper.Sort{
+		kendohelper.SortElem{"Pinned", "desc"},
+		kendohelper.SortElem{"Age", "asc"},
+		kendohelper.SortElem{"Name", "asc"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("prepend should be %v, got %v", expected, result)
+	}
+	result[2].Field = "name"
+	if sort[0].Field != "Name" {
+		t.Errorf("prepend should not share elements with the original sort, got %v", sort)
+	}
+}
+
+func TestSortWithTieBreaker(t *testing.T) {
+	tt := []struct {
+		name     string
+		sort     kendohelper.Sort
+		expected kendohelper.Sort
+	}{
+		{
+			name: "append tie-breaker",
+			sort: kendohelper.Sort{
+				kendohelper.SortElem{"Name", "asc"},
+			},
+			expected: kendohelper.Sort{
+				kendohelper.SortElem{"Name", "asc"},
+				kendohelper.SortElem{"_id", "asc"},
+			},
+		},
+		{
+			name: "already sorted by tie-breaker",
+			sort: kendohelper.Sort{
+				kendohelper.SortElem{"_id", "desc"},
+				kendohelper.SortElem{"Name", "asc"},
+			},
+			expected: kendohelper.Sort{
+				kendohelper.SortElem{"_id", "desc"},
+				kendohelper.SortElem{"Name", "asc"},
+			},
+		},
+		{
+			name: "empty sort",
+			sort: kendohelper.Sort{},
+			expected: kendohelper.Sort{
+				kendohelper.SortElem{"_id", "asc"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.sort.WithTieBreaker("_id")
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"context"
+
+	"github.com/eaciit/toolkit"
+	"gopkg.in/mgo.v2"
+)
+
+// Source queries rows of a grid, grid handlers depending on it can be tested with MemorySource.
+type Source[T any] interface {
+	// Query returns the rows matching filter sorted by sort, skipping skip rows and returning at most take rows (zero means all).
+	Query(ctx context.Context, filter Filter, sort Sort, skip, take int) ([]T, error)
+	// Count returns the number of rows matching filter.
+	Count(ctx context.Context, filter Filter) (int, error)
+}
+
+// Fetch queries the page of request and the total count from source.
+func Fetch[T any](ctx context.Context, source Source[T], request Request) ([]T, int, error) {
+	rows, err := source.Query(ctx, request.Filter, request.Sort, request.Offset(), request.Limit())
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := source.Count(ctx, request.Filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// MemorySource is Source of rows in memory (maps or structs), using Filter's Match and Sort's SortRows.
+type MemorySource[T any] struct {
+	Rows []T
+}
+
+// NewMemorySource creates MemorySource of rows.
+func NewMemorySource[T any](rows ...T) *MemorySource[T] {
+	return &MemorySource[T]{Rows: rows}
+}
+
+// Query implements Source.
+func (s *MemorySource[T]) Query(ctx context.Context, filter Filter, sort Sort, skip, take int) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rows := s.match(filter)
+	sort.SortRows(rows)
+	if skip > len(rows) {
+		skip = len(rows)
+	}
+	rows = rows[skip:]
+	if take > 0 && take < len(rows) {
+		rows = rows[:take]
+	}
+	return rows, nil
+}
+
+// Count implements Source.
+func (s *MemorySource[T]) Count(ctx context.Context, filter Filter) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return len(s.match(filter)), nil
+}
+
+func (s *MemorySource[T]) match(filter Filter) []T {
+	rows := []T{}
+	for i := range s.Rows {
+		if filter.Match(s.Rows[i]) {
+			rows = append(rows, s.Rows[i])
+		}
+	}
+	return rows
+}
+
+// MongoSource is Source of a mongo collection, using ToAggregateFilter and ToAggregateSort.
+// Stages (e.g. $lookup, $addFields) run before $match, so the grid can filter and sort their fields.
+type MongoSource[T any] struct {
+	Collection *mgo.Collection
+	Stages     []toolkit.M
+}
+
+// NewMongoSource creates MongoSource of collection.
+func NewMongoSource[T any](collection *mgo.Collection, stages ...toolkit.M) *MongoSource[T] {
+	return &MongoSource[T]{Collection: collection, Stages: stages}
+}
+
+// Query implements Source, ctx is only checked before querying since mgo doesn't support it.
+func (s *MongoSource[T]) Query(ctx context.Context, filter Filter, sort Sort, skip, take int) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	request := Request{Filter: filter, Sort: sort}
+	pipe := request.matchPipeline(s.Stages)
+	if aggregateSort := sort.ToAggregateSort(); len(aggregateSort) != 0 {
+		pipe = append(pipe, toolkit.M{"$sort": aggregateSort})
+	}
+	if skip > 0 {
+		pipe = append(pipe, toolkit.M{"$skip": skip})
+	}
+	if take > 0 {
+		pipe = append(pipe, toolkit.M{"$limit": take})
+	}
+
+	rows := []T{}
+	if err := s.Collection.Pipe(pipe).All(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Count implements Source, ctx is only checked before querying since mgo doesn't support it.
+func (s *MongoSource[T]) Count(ctx context.Context, filter Filter) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	request := Request{Filter: filter}
+	result := struct {
+		Total int `bson:"total"`
+	}{}
+	err := s.Collection.Pipe(request.ToCountPipeline(s.Stages...)).One(&result)
+	if err == mgo.ErrNotFound {
+		return 0, nil
+	}
+	return result.Total, err
+}
@@ -0,0 +1,72 @@
+package kendohelper_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/muktihari/kendohelper"
+)
+
+type sourceUser struct {
+	Name string
+	Age  int
+}
+
+func TestMemorySource(t *testing.T) {
+	var source kendohelper.Source[sourceUser] = kendohelper.NewMemorySource(
+		sourceUser{"Hari", 25},
+		sourceUser{"Surya", 30},
+		sourceUser{"Radit", 27},
+		sourceUser{"Dewa", 20},
+	)
+	filter := kendohelper.Filter{"", "", "", []kendohelper.Filter{kendohelper.Filter{"Age", "gte", 21, nil, ""}}, "and"}
+	sort := kendohelper.Sort{kendohelper.SortElem{"Age", "desc"}}
+
+	tt := []struct {
+		name     string
+		skip     int
+		take     int
+		expected []sourceUser
+	}{
+		{"all", 0, 0, []sourceUser{sourceUser{"Surya", 30}, sourceUser{"Radit", 27}, sourceUser{"Hari", 25}}},
+		{"page", 1, 1, []sourceUser{sourceUser{"Radit", 27}}},
+		{"beyond the last page", 5, 10, []sourceUser{}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := source.Query(context.Background(), filter, sort, tc.skip, tc.take)
+			if err != nil {
+				t.Fatalf("%v unexpected error: %v", tc.name, err)
+			}
+			if !reflect.DeepEqual(rows, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, rows)
+			}
+		})
+	}
+
+	count, err := source.Count(context.Background(), filter)
+	if err != nil || count != 3 {
+		t.Errorf("count should be 3, got %v %v", count, err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	source := kendohelper.NewMemorySource(sourceUser{"Hari", 25}, sourceUser{"Surya", 30}, sourceUser{"Radit", 27})
+	request := kendohelper.Request{Page: 2, PageSize: 2, Sort: kendohelper.Sort{kendohelper.SortElem{"Name", "asc"}}}
+
+	rows, total, err := kendohelper.Fetch[sourceUser](context.Background(), source, request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []sourceUser{sourceUser{"Surya", 30}}; !reflect.DeepEqual(rows, expected) || total != 3 {
+		t.Errorf("fetch should be %v and 3, got %v and %v", expected, rows, total)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := kendohelper.Fetch[sourceUser](ctx, source, request); err != context.Canceled {
+		t.Errorf("canceled context should be returned, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ *
+ * References:
+ * https://docs.mongodb.com/manual/reference/operator/aggregation/addFields/
+ * https://docs.mongodb.com/manual/reference/operator/query/expr/
+ */
+
+import (
+	"sort"
+
+	"github.com/eaciit/dbox"
+	"github.com/eaciit/toolkit"
+)
+
+// VirtualFields maps computed fields to their aggregation expressions, so they can be filtered and sorted
+// without being stored in every document, e.g.
+//
+//	kendohelper.VirtualFields{
+//		"fullName": toolkit.M{"$concat": []interface{}{"$firstName", " ", "$lastName"}},
+//		"margin":   toolkit.M{"$subtract": []interface{}{"$price", "$cost"}},
+//	}
+type VirtualFields map[string]interface{}
+
+// Stage returns $addFields stage of fields (all virtual fields when none is given), fields which aren't virtual are skipped.
+// It returns nil when there's no field to add.
+func (v VirtualFields) Stage(fields ...string) toolkit.M {
+	if len(fields) == 0 {
+		for field := range v {
+			fields = append(fields, field)
+		}
+	}
+	addFields := toolkit.M{}
+	for _, field := range fields {
+		if expression, ok := v[field]; ok {
+			addFields[field] = expression
+		}
+	}
+	if len(addFields) == 0 {
+		return nil
+	}
+	return toolkit.M{"$addFields": addFields}
+}
+
+// Stages returns $addFields stage of the virtual fields used by the filter, sort, group or aggregate of request, or nil when none is used.
+// Put them before $match, e.g. request.ToAggregatePipeline(virtualFields.Stages(request)...).
+func (v VirtualFields) Stages(request Request) []toolkit.M {
+	used := map[string]bool{}
+	for _, group := range request.Group {
+		used[group.Field] = true
+		for _, aggregate := range group.Aggregates {
+			used[aggregate.Field] = true
+		}
+	}
+	for _, aggregate := range request.Aggregate {
+		used[aggregate.Field] = true
+	}
+
+	fields := []string{}
+	for field := range v {
+		if used[field] || request.Filter.HasField(field) || request.Sort.HasField(field) {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	sort.Strings(fields)
+	return []toolkit.M{v.Stage(fields...)}
+}
+
+// ToAggregateFilter converts Filter to $match like Filter's ToAggregateFilter, except filters on virtual fields are expanded into $expr,
+// so no $addFields stage is needed. Unlike $match, $expr compares values of different types in BSON order, except that
+// "lt" and "lte" don't match null. "startswith", "contains", etc. need MongoDB 4.2 ($regexMatch).
+func (v VirtualFields) ToAggregateFilter(filter Filter) toolkit.M {
+	if len(filter.Filters) == 0 {
+		expression, ok := v[filter.Field]
+		if !ok {
+			return filter.ToAggregateFilter()
+		}
+		expr := virtualExpr(expression, filter.Operator, filter.Value)
+		if expr == nil {
+			return nil
+		}
+		return toolkit.M{"$expr": expr}
+	}
+
+	matches := []toolkit.M{}
+	for _, f := range filter.Filters {
+		if match := v.ToAggregateFilter(f); match != nil {
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	switch filter.Logic {
+	case "not":
+		if len(matches) == 1 {
+			return toolkit.M{"$nor": matches}
+		}
+		return toolkit.M{"$nor": []toolkit.M{toolkit.M{"$and": matches}}}
+	case "and", "or":
+		return toolkit.M{"$" + filter.Logic: matches}
+	}
+	return nil
+}
+
+// ToDBOXFilter converts Filter to dbox.Filter like Filter's ToDBOXFilter, except filters on virtual fields are expanded into $expr
+// (see ToAggregateFilter), passed as the value of an "$expr" field. Only dbox's mongo driver understands them.
+// "not" of filters on virtual fields is passed the same way as "$nor". It returns DefaultDBOXFilter() when no filter is generated.
+func (v VirtualFields) ToDBOXFilter(filter Filter) *dbox.Filter {
+	if !v.hasField(filter) {
+		return filter.ToDBOXFilter()
+	}
+	if len(filter.Filters) == 0 || filter.Logic == "not" {
+		match := v.ToAggregateFilter(filter)
+		for field, value := range match {
+			return &dbox.Filter{Field: field, Op: dbox.FilterOpEqual, Value: value}
+		}
+		return defaultDBOXFilter
+	}
+
+	dboxFilters := []*dbox.Filter{}
+	for _, f := range filter.Filters {
+		if dboxFilter := v.ToDBOXFilter(f); dboxFilter != defaultDBOXFilter {
+			dboxFilters = append(dboxFilters, dboxFilter)
+		}
+	}
+	if len(dboxFilters) == 0 {
+		return defaultDBOXFilter
+	}
+	switch filter.Logic {
+	case "and":
+		return dbox.And(dboxFilters...)
+	case "or":
+		return dbox.Or(dboxFilters...)
+	}
+	return defaultDBOXFilter
+}
+
+// hasField checks whether filter has any virtual field.
+func (v VirtualFields) hasField(filter Filter) bool {
+	for field := range v {
+		if filter.HasField(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// virtualExpr returns the aggregation expression of operator on expression, nil when it's ignored by ToAggregateFilter.
+func virtualExpr(expression interface{}, operator string, value interface{}) interface{} {
+	value = normalizeValue(value)
+	valueStr, isString := value.(string)
+	if isString {
+		if t, ok := parseDefaultDate(valueStr); ok {
+			value = t
+		}
+	}
+
+	switch operator {
+	case "isnull":
+		return toolkit.M{"$eq": []interface{}{toolkit.M{"$ifNull": []interface{}{expression, nil}}, nil}}
+	case "isnotnull":
+		return toolkit.M{"$ne": []interface{}{toolkit.M{"$ifNull": []interface{}{expression, nil}}, nil}}
+	case "eq", "gt", "gte":
+		return toolkit.M{"$" + operator: []interface{}{expression, value}}
+	case "neq":
+		return toolkit.M{"$ne": []interface{}{expression, value}}
+	case "lt", "lte":
+		return toolkit.M{"$and": []interface{}{
+			toolkit.M{"$" + operator: []interface{}{expression, value}},
+			toolkit.M{"$gt": []interface{}{expression, nil}},
+		}}
+	}
+
+	if !isString {
+		return nil
+	}
+	switch operator {
+	case "startswith", "endswith", "contains":
+		return virtualRegexMatch(expression, operator, valueStr)
+	case "doesnotstartwith", "doesnotendwith", "doesnotcontain":
+		positive := map[string]string{"doesnotstartwith": "startswith", "doesnotendwith": "endswith", "doesnotcontain": "contains"}
+		return toolkit.M{"$not": []interface{}{virtualRegexMatch(expression, positive[operator], valueStr)}}
+	case "isempty":
+		return toolkit.M{"$eq": []interface{}{expression, ""}}
+	case "isnotempty":
+		return toolkit.M{"$ne": []interface{}{expression, ""}}
+	}
+	return nil
+}
+
+// virtualRegexMatch matches the same regex used by ToAggregateFilter, only on strings since $regexMatch fails on other types.
+func virtualRegexMatch(expression interface{}, operator, value string) toolkit.M {
+	pattern := `.*` + value + `.*`
+	switch operator {
+	case "startswith":
+		pattern = `^` + value
+	case "endswith":
+		pattern = value + `$`
+	}
+	return toolkit.M{"$and": []interface{}{
+		toolkit.M{"$eq": []interface{}{toolkit.M{"$type": expression}, "string"}},
+		toolkit.M{"$regexMatch": toolkit.M{"input": expression, "regex": pattern, "options": "i"}},
+	}}
+}
@@ -0,0 +1,134 @@
+package kendohelper_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eaciit/dbox"
+	"github.com/eaciit/toolkit"
+	"github.com/muktihari/kendohelper"
+)
+
+var testVirtualFields = kendohelper.VirtualFields{
+	"fullName": toolkit.M{"$concat": []interface{}{"$firstName", " ", "$lastName"}},
+	"margin":   toolkit.M{"$subtract": []interface{}{"$price", "$cost"}},
+}
+
+func TestVirtualFieldsStages(t *testing.T) {
+	tt := []struct {
+		name     string
+		request  kendohelper.Request
+		expected []toolkit.M
+	}{
+		{"none used", kendohelper.Request{Filter: kendohelper.Filter{"price", "gt", 10, nil, ""}}, nil},
+		{"filter and sort", kendohelper.Request{
+			Filter: kendohelper.Filter{"margin", "gt", 100, nil, ""},
+			Sort:   kendohelper.Sort{kendohelper.SortElem{"fullName", "asc"}},
+		}, []toolkit.M{toolkit.M{"$addFields": toolkit.M{
+			"fullName": testVirtualFields["fullName"],
+			"margin":   testVirtualFields["margin"],
+		}}}},
+		{"aggregate", kendohelper.Request{
+			Aggregate: []kendohelper.AggregateElem{{Field: "margin", Aggregate: "sum"}},
+		}, []toolkit.M{toolkit.M{"$addFields": toolkit.M{"margin": testVirtualFields["margin"]}}}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			stages := testVirtualFields.Stages(tc.request)
+			if !reflect.DeepEqual(stages, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, stages)
+			}
+		})
+	}
+
+	if stage := testVirtualFields.Stage(); len(stage["$addFields"].(toolkit.M)) != 2 {
+		t.Errorf("stage should add all virtual fields, got %v", stage)
+	}
+	if stage := testVirtualFields.Stage("price"); stage != nil {
+		t.Errorf("stage of no virtual field should be nil, got %v", stage)
+	}
+}
+
+func TestVirtualFieldsToAggregateFilter(t *testing.T) {
+	margin := testVirtualFields["margin"]
+	fullName := testVirtualFields["fullName"]
+
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected toolkit.M
+	}{
+		{"stored field", kendohelper.Filter{"price", "gt", 10, nil, ""}, toolkit.M{"price": toolkit.M{"$gt": 10}}},
+		{"gt", kendohelper.Filter{"margin", "gt", 100, nil, ""},
+			toolkit.M{"$expr": toolkit.M{"$gt": []interface{}{margin, 100}}}},
+		{"lt doesn't match null", kendohelper.Filter{"margin", "lt", 0, nil, ""},
+			toolkit.M{"$expr": toolkit.M{"$and": []interface{}{
+				toolkit.M{"$lt": []interface{}{margin, 0}},
+				toolkit.M{"$gt": []interface{}{margin, nil}},
+			}}}},
+		{"contains", kendohelper.Filter{"fullName", "contains", "hari", nil, ""},
+			toolkit.M{"$expr": toolkit.M{"$and": []interface{}{
+				toolkit.M{"$eq": []interface{}{toolkit.M{"$type": fullName}, "string"}},
+				toolkit.M{"$regexMatch": toolkit.M{"input": fullName, "regex": ".*hari.*", "options": "i"}},
+			}}}},
+		{"isnull", kendohelper.Filter{"margin", "isnull", nil, nil, ""},
+			toolkit.M{"$expr": toolkit.M{"$eq": []interface{}{toolkit.M{"$ifNull": []interface{}{margin, nil}}, nil}}}},
+		{"ignored", kendohelper.Filter{"margin", "contains", 1, nil, ""}, nil},
+		{"mixed", kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{"price", "gt", 10, nil, ""},
+			kendohelper.Filter{"margin", "neq", 0, nil, ""},
+		}, "or"}, toolkit.M{"$or": []toolkit.M{
+			toolkit.M{"price": toolkit.M{"$gt": 10}},
+			toolkit.M{"$expr": toolkit.M{"$ne": []interface{}{margin, 0}}},
+		}}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			match := testVirtualFields.ToAggregateFilter(tc.filter)
+			if !reflect.DeepEqual(match, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, match)
+			}
+		})
+	}
+}
+
+func TestVirtualFieldsToDBOXFilter(t *testing.T) {
+	margin := testVirtualFields["margin"]
+
+	tt := []struct {
+		name     string
+		filter   kendohelper.Filter
+		expected *dbox.Filter
+	}{
+		{"stored field", kendohelper.Filter{"price", "gt", 10, nil, ""}, dbox.Gt("price", 10)},
+		{"gt", kendohelper.Filter{"margin", "gt", 100, nil, ""},
+			dbox.Eq("$expr", toolkit.M{"$gt": []interface{}{margin, 100}})},
+		{"ignored", kendohelper.Filter{"margin", "contains", 1, nil, ""}, kendohelper.DefaultDBOXFilter()},
+		{"mixed", kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{"price", "gt", 10, nil, ""},
+			kendohelper.Filter{"margin", "neq", 0, nil, ""},
+		}, "or"}, dbox.Or(
+			dbox.Gt("price", 10),
+			dbox.Eq("$expr", toolkit.M{"$ne": []interface{}{margin, 0}}),
+		)},
+		{"not", kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{"margin", "gt", 100, nil, ""},
+		}, "not"}, dbox.Eq("$nor", []toolkit.M{
+			toolkit.M{"$expr": toolkit.M{"$gt": []interface{}{margin, 100}}},
+		})},
+		{"not of stored field", kendohelper.Filter{"", "", nil, []kendohelper.Filter{
+			kendohelper.Filter{"price", "eq", 10, nil, ""},
+		}, "not"}, dbox.Ne("price", 10)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dboxFilter := testVirtualFields.ToDBOXFilter(tc.filter)
+			if !reflect.DeepEqual(dboxFilter, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, dboxFilter)
+			}
+		})
+	}
+}
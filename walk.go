@@ -0,0 +1,124 @@
+package kendohelper
+
+/* @Author
+ * Hikmatulloh Hari Mukti <hikmatullohhari@gmail.com>
+ */
+
+import (
+	"errors"
+)
+
+// WalkAction tells Walk what to do after visiting a filter.
+type WalkAction int
+
+const (
+	// WalkContinue keeps walking, the returned filter replaces the visited one.
+	WalkContinue WalkAction = iota
+	// WalkSkip keeps walking but doesn't visit the nested filters of the returned filter (pre-order only).
+	WalkSkip
+	// WalkRemove removes the visited filter from its parent.
+	WalkRemove
+	// WalkStop stops walking, the returned filter still replaces the visited one.
+	WalkStop
+)
+
+// WalkInfo describes where the visited filter is.
+type WalkInfo struct {
+	// Path is the indexes of the visited filter from the root, empty for the root.
+	Path []int
+	// ParentLogic is the logic of the parent filter, empty for the root.
+	ParentLogic string
+	// Leaf is true when the visited filter has no nested filters.
+	Leaf bool
+}
+
+// WalkFunc visits a filter (groups included) and returns the filter replacing it along with the next action.
+// Returning a different filter replaces the whole subtree.
+type WalkFunc func(filter Filter, info WalkInfo) (Filter, WalkAction)
+
+// WalkErrFunc is WalkFunc that may return an error to abort walking.
+type WalkErrFunc func(filter Filter, info WalkInfo) (Filter, WalkAction, error)
+
+var errWalkStop = errors.New("kendohelper: walk stopped")
+
+// Walk visits every filter, groups included, calling pre before visiting nested filters and post after.
+// Either pre or post can be nil. Unlike Handle, it can rewrite groups, remove filters and stop walking.
+// A removed root becomes an empty Filter.
+func (f *Filter) Walk(pre, post WalkFunc) {
+	f.WalkErr(wrapWalkFunc(pre), wrapWalkFunc(post))
+}
+
+// WalkErr is Walk whose funcs may return an error, walking is aborted and the error is returned as is.
+// Changes made before the error stay, use DeepClone to keep the original filter intact.
+func (f *Filter) WalkErr(pre, post WalkErrFunc) error {
+	filter, remove, err := walk(*f, WalkInfo{Path: []int{}, Leaf: len(f.Filters) == 0}, pre, post)
+	if remove {
+		filter = Filter{}
+	}
+	*f = filter
+	if err == errWalkStop {
+		return nil
+	}
+	return err
+}
+
+func wrapWalkFunc(fn WalkFunc) WalkErrFunc {
+	if fn == nil {
+		return nil
+	}
+	return func(filter Filter, info WalkInfo) (Filter, WalkAction, error) {
+		filter, action := fn(filter, info)
+		return filter, action, nil
+	}
+}
+
+func walk(filter Filter, info WalkInfo, pre, post WalkErrFunc) (Filter, bool, error) {
+	action := WalkContinue
+	if pre != nil {
+		var err error
+		filter, action, err = pre(filter, info)
+		if err != nil {
+			return filter, false, err
+		}
+		switch action {
+		case WalkRemove:
+			return filter, true, nil
+		case WalkStop:
+			return filter, false, errWalkStop
+		}
+	}
+
+	if action != WalkSkip && len(filter.Filters) != 0 {
+		filters := make([]Filter, 0, len(filter.Filters))
+		for i := range filter.Filters {
+			path := append(append(make([]int, 0, len(info.Path)+1), info.Path...), i)
+			child := filter.Filters[i]
+			child, remove, err := walk(child, WalkInfo{path, filter.Logic, len(child.Filters) == 0}, pre, post)
+			if !remove {
+				filters = append(filters, child)
+			}
+			if err != nil {
+				filters = append(filters, filter.Filters[i+1:]...)
+				filter.Filters = filters
+				return filter, false, err
+			}
+		}
+		filter.Filters = filters
+	}
+
+	if post != nil {
+		info.Leaf = len(filter.Filters) == 0
+		var err error
+		filter, action, err = post(filter, info)
+		if err != nil {
+			return filter, false, err
+		}
+		switch action {
+		case WalkRemove:
+			return filter, true, nil
+		case WalkStop:
+			return filter, false, errWalkStop
+		}
+	}
+	return filter, false, nil
+}
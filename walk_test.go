@@ -0,0 +1,192 @@
+package kendohelper_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/muktihari/kendohelper"
+)
+
+func TestFilterWalk(t *testing.T) {
+	newFilter := func() kendohelper.Filter {
+		return kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+			kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"commission_fee", "gt", 10, nil, ""},
+				kendohelper.Filter{"Age", "gte", 25, nil, ""},
+			}, "and"},
+			kendohelper.Filter{"commission_fee", "lt", 5, nil, ""},
+		}, "or"}
+	}
+
+	tt := []struct {
+		name     string
+		pre      kendohelper.WalkFunc
+		post     kendohelper.WalkFunc
+		expected kendohelper.Filter
+	}{
+		{
+			name: "remove leaves",
+			pre: func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction) {
+				if filter.Field == "commission_fee" {
+					return filter, kendohelper.WalkRemove
+				}
+				return filter, kendohelper.WalkContinue
+			},
+			expected: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"Age", "gte", 25, nil, ""},
+				}, "and"},
+			}, "or"},
+		},
+		{
+			name: "rewrite groups",
+			pre: func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction) {
+				if filter.Logic == "and" {
+					filter.Logic = "or"
+				}
+				return filter, kendohelper.WalkContinue
+			},
+			expected: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"commission_fee", "gt", 10, nil, ""},
+					kendohelper.Filter{"Age", "gte", 25, nil, ""},
+				}, "or"},
+				kendohelper.Filter{"commission_fee", "lt", 5, nil, ""},
+			}, "or"},
+		},
+		{
+			name: "replace subtree and skip it",
+			pre: func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction) {
+				if filter.Field == "Name" {
+					return kendohelper.Filter{"", "", "", []kendohelper.Filter{
+						kendohelper.Filter{"FirstName", "eq", "Hari", nil, ""},
+						kendohelper.Filter{"LastName", "eq", "Hari", nil, ""},
+					}, "or"}, kendohelper.WalkSkip
+				}
+				if filter.Field != "" {
+					filter.Operator = "eq"
+				}
+				return filter, kendohelper.WalkContinue
+			},
+			expected: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"FirstName", "eq", "Hari", nil, ""},
+					kendohelper.Filter{"LastName", "eq", "Hari", nil, ""},
+				}, "or"},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"commission_fee", "eq", 10, nil, ""},
+					kendohelper.Filter{"Age", "eq", 25, nil, ""},
+				}, "and"},
+				kendohelper.Filter{"commission_fee", "eq", 5, nil, ""},
+			}, "or"},
+		},
+		{
+			name: "stop",
+			pre: func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction) {
+				if filter.Field == "commission_fee" {
+					filter.Operator = ""
+					return filter, kendohelper.WalkStop
+				}
+				return filter, kendohelper.WalkContinue
+			},
+			expected: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+				kendohelper.Filter{"", "", "", []kendohelper.Filter{
+					kendohelper.Filter{"commission_fee", "", 10, nil, ""},
+					kendohelper.Filter{"Age", "gte", 25, nil, ""},
+				}, "and"},
+				kendohelper.Filter{"commission_fee", "lt", 5, nil, ""},
+			}, "or"},
+		},
+		{
+			name: "post-order prunes emptied groups",
+			pre: func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction) {
+				if filter.Field == "commission_fee" || filter.Field == "Age" {
+					return filter, kendohelper.WalkRemove
+				}
+				return filter, kendohelper.WalkContinue
+			},
+			post: func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction) {
+				if filter.Field == "" && info.Leaf {
+					return filter, kendohelper.WalkRemove
+				}
+				return filter, kendohelper.WalkContinue
+			},
+			expected: kendohelper.Filter{"", "", "", []kendohelper.Filter{
+				kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+			}, "or"},
+		},
+		{
+			name: "remove root",
+			pre: func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction) {
+				return filter, kendohelper.WalkRemove
+			},
+			expected: kendohelper.Filter{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := newFilter()
+			filter.Walk(tc.pre, tc.post)
+			if !reflect.DeepEqual(filter, tc.expected) {
+				t.Errorf("%v should be %v, got %v", tc.name, tc.expected, filter)
+			}
+		})
+	}
+}
+
+func TestFilterWalkInfo(t *testing.T) {
+	filter := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+		kendohelper.Filter{"", "", "", []kendohelper.Filter{
+			kendohelper.Filter{"Age", "gte", 25, nil, ""},
+		}, "and"},
+	}, "or"}
+
+	visits := []kendohelper.WalkInfo{}
+	filter.Walk(nil, func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction) {
+		visits = append(visits, info)
+		return filter, kendohelper.WalkContinue
+	})
+	expected := []kendohelper.WalkInfo{
+		kendohelper.WalkInfo{[]int{0}, "or", true},
+		kendohelper.WalkInfo{[]int{1, 0}, "and", true},
+		kendohelper.WalkInfo{[]int{1}, "or", false},
+		kendohelper.WalkInfo{[]int{}, "", false},
+	}
+	if !reflect.DeepEqual(visits, expected) {
+		t.Errorf("post-order visits should be %v, got %v", expected, visits)
+	}
+}
+
+func TestFilterWalkErr(t *testing.T) {
+	errRestricted := errors.New("commission_fee is restricted")
+	filter := kendohelper.Filter{"", "", "", []kendohelper.Filter{
+		kendohelper.Filter{"Name", "eq", "Hari", nil, ""},
+		kendohelper.Filter{"commission_fee", "gt", 10, nil, ""},
+		kendohelper.Filter{"Age", "gte", 25, nil, ""},
+	}, "and"}
+
+	visited := 0
+	err := filter.WalkErr(func(filter kendohelper.Filter, info kendohelper.WalkInfo) (kendohelper.Filter, kendohelper.WalkAction, error) {
+		visited++
+		if filter.Field == "commission_fee" {
+			return filter, kendohelper.WalkContinue, errRestricted
+		}
+		return filter, kendohelper.WalkContinue, nil
+	}, nil)
+	if err != errRestricted {
+		t.Errorf("error should be %v, got %v", errRestricted, err)
+	}
+	if visited != 3 {
+		t.Errorf("walk should abort after 3 visits, got %v", visited)
+	}
+	if len(filter.Filters) != 3 {
+		t.Errorf("aborted walk should keep the rest of filters, got %v", filter)
+	}
+}